@@ -0,0 +1,178 @@
+package getopt
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vtudorache/go-utils/properties"
+)
+
+//go:embed messages/*.properties
+var messages embed.FS
+
+// A Catalog holds the messages shown to the user, keyed by message ID. The
+// arguments of a message are written as {0}, {1} and so on.
+type Catalog struct {
+	table *properties.Table
+}
+
+// Locale returns the locale of the messages, taken from the first of the
+// environment variables LC_ALL, LC_MESSAGES and LANG that is set and not
+// empty. The codeset and the modifier are removed, so "fr_CA.UTF-8" gives
+// "fr_CA". The locales "C" and "POSIX" give the empty string, as does a
+// missing locale. If getenv is nil, os.Getenv is used.
+func Locale(getenv func(string) string) string {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		s := getenv(name)
+		if s == "" {
+			continue
+		}
+		if i := strings.IndexAny(s, ".@"); i >= 0 {
+			s = s[:i]
+		}
+		if s == "C" || s == "POSIX" {
+			return ""
+		}
+		return strings.ReplaceAll(s, "-", "_")
+	}
+	return ""
+}
+
+// LoadCatalog loads a catalog from the property files of fsys named after
+// base and locale. For the locale "fr_CA" and the base "msg", the files are
+// "msg.properties", "msg_fr.properties" and "msg_fr_CA.properties". A
+// message missing from a file is searched in the previous one, using the
+// 'defaults' tables of properties.Table. Missing files are skipped.
+func LoadCatalog(fsys fs.FS, base, locale string) (*Catalog, error) {
+	t := new(properties.Table)
+	name := base
+	parts := strings.Split(locale, "_")
+	for i := -1; i < len(parts); i++ {
+		if i >= 0 {
+			if parts[i] == "" {
+				break
+			}
+			name += "_" + parts[i]
+			t = properties.NewTableDefaults(t)
+		}
+		f, e := fsys.Open(name + ".properties")
+		if errors.Is(e, fs.ErrNotExist) {
+			continue
+		}
+		if e != nil {
+			return nil, e
+		}
+		_, e = t.Load(f)
+		f.Close()
+		if e != nil {
+			return nil, e
+		}
+	}
+	return &Catalog{t}, nil
+}
+
+// DefaultCatalog returns the catalog of the messages of this package, for
+// the locale given by Locale(nil).
+func DefaultCatalog() *Catalog {
+	c, e := LoadCatalog(messages, "messages/getopt", Locale(nil))
+	if e != nil {
+		return &Catalog{new(properties.Table)}
+	}
+	return c
+}
+
+// Message returns the message identified by id, with its arguments replaced
+// by args. If the message isn't found, it returns id.
+func (c *Catalog) Message(id string, args ...string) string {
+	s, found := c.table.Lookup(id)
+	if !found {
+		return id
+	}
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			break
+		}
+		n, e := strconv.Atoi(s[i+1 : i+j])
+		b.WriteString(s[:i])
+		if e == nil && n >= 0 && n < len(args) {
+			b.WriteString(args[n])
+		} else {
+			b.WriteString(s[i : i+j+1])
+		}
+		s = s[i+j+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// SetCatalog sets the catalog of the messages shown by the parser. If c is
+// nil, the parser uses DefaultCatalog.
+func (p *Parser) SetCatalog(c *Catalog) {
+	p.messages = c
+}
+
+// catalog returns the catalog of the messages shown by the parser.
+func (p *Parser) catalog() *Catalog {
+	if p.messages == nil {
+		p.messages = DefaultCatalog()
+	}
+	return p.messages
+}
+
+// Message returns the text describing the error e, returned by the last
+// call to Option, in the language of the catalog of the parser. The text
+// doesn't start with "getopt: ", so that it can be preceded by the name of
+// the program.
+func (p *Parser) Message(e error) string {
+	c := p.catalog()
+	var v *ValueError
+	if errors.As(e, &v) {
+		option, value := v.Option, strconv.Quote(v.Value)
+		switch {
+		case errors.Is(v.Err, ErrKey):
+			return c.Message("key.unknown", option)
+		case errors.Is(v.Err, ErrChoice):
+			return c.Message("value.choice", option, value)
+		case errors.Is(v.Err, ErrRange):
+			return c.Message("value.range", option, value)
+		case errors.Is(v.Err, ErrMatch):
+			return c.Message("value.match", option, value)
+		case errors.Is(v.Err, fs.ErrNotExist):
+			return c.Message("value.notexist", option, value)
+		case errors.Is(v.Err, ErrNotDir):
+			return c.Message("value.notdir", option, value)
+		case errors.Is(v.Err, ErrReadOnly):
+			return c.Message("value.readonly", option, value)
+		case errors.Is(v.Err, ErrSize):
+			return c.Message("value.size", option, value)
+		}
+		return c.Message("value.invalid", option, value,
+			strings.TrimPrefix(v.Err.Error(), "getopt: "))
+	}
+	switch {
+	case errors.Is(e, ErrOption):
+		return c.Message("option.unknown", p.optName)
+	case errors.Is(e, ErrNoArg):
+		return c.Message("option.noarg", p.optName)
+	case errors.Is(e, ErrFewArgs):
+		return c.Message("option.fewargs", p.optName)
+	case errors.Is(e, ErrArg):
+		return c.Message("option.arg", p.optName)
+	case errors.Is(e, ErrRequired):
+		return c.Message("option.required", p.optName)
+	}
+	return strings.TrimPrefix(e.Error(), "getopt: ")
+}
@@ -0,0 +1,79 @@
+package getopt
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		env    map[string]string
+		locale string
+	}{
+		{map[string]string{"LANG": "fr_CA.UTF-8"}, "fr_CA"},
+		{map[string]string{"LANG": "fr_FR", "LC_MESSAGES": "de_DE@euro"}, "de_DE"},
+		{map[string]string{"LANG": "fr_FR", "LC_ALL": "C"}, ""},
+		{map[string]string{"LC_ALL": "", "LANG": "de-AT"}, "de_AT"},
+		{map[string]string{}, ""},
+	}
+	for _, test := range tests {
+		locale := Locale(func(name string) string { return test.env[name] })
+		if locale != test.locale {
+			t.Errorf("%v: Locale() returned %q, not %q", test.env, locale, test.locale)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	fsys := fstest.MapFS{
+		"msg.properties":       {Data: []byte("a=root {0}\nb=root\nc=root\n")},
+		"msg_fr.properties":    {Data: []byte("a=fr {0} {1}\nb=fr\n")},
+		"msg_fr_CA.properties": {Data: []byte("a=fr_CA {1}{0}\n")},
+	}
+	tests := []struct {
+		locale, a, b, c string
+	}{
+		{"fr_CA", "fr_CA yx", "fr", "root"},
+		{"fr_BE", "fr x y", "fr", "root"},
+		{"de", "root x", "root", "root"},
+		{"", "root x", "root", "root"},
+	}
+	for _, test := range tests {
+		c, e := LoadCatalog(fsys, "msg", test.locale)
+		if e != nil {
+			t.Fatal(e)
+		}
+		a, b, x := c.Message("a", "x", "y"), c.Message("b"), c.Message("c")
+		if a != test.a || b != test.b || x != test.c {
+			t.Errorf("%q: messages are %q, %q, %q", test.locale, a, b, x)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		locale, message string
+	}{
+		{"", "invalid value \"blue\" for option -c: not among the choices"},
+		{"fr_FR", "valeur \"blue\" invalide pour l'option -c : choix non autorisé"},
+		{"de_DE", "ungültiger Wert \"blue\" für Option -c: kein erlaubter Wert"},
+	}
+	for _, test := range tests {
+		c, e := LoadCatalog(messages, "messages/getopt", test.locale)
+		if e != nil {
+			t.Fatal(e)
+		}
+		p := NewParser([]string{"test", "-c", "blue", "-x"}, "c:")
+		p.SetCatalog(c)
+		p.SetChoices('c', "red", "green")
+		_, e = p.Option()
+		if m := p.Message(e); m != test.message {
+			t.Errorf("%q: Message() returned %q", test.locale, m)
+		}
+		_, e = p.Option()
+		if !errors.Is(e, ErrOption) || p.Message(e) == "option.unknown" {
+			t.Errorf("%q: Message() returned %q", test.locale, p.Message(e))
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package clitest runs command-line programs in-process, for testing. A
+// program is written as a Command taking its arguments, environment, input,
+// output and files from an Env. Test cases may be given as tables of Case
+// or as golden scripts in the txtar format.
+package clitest
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Env is the environment of a Command.
+type Env struct {
+	Args   []string            // the arguments, the first one being the name
+	Getenv func(string) string // returns the environment variables
+	Stdin  io.Reader           // the standard input
+	Stdout io.Writer           // the standard output
+	Stderr io.Writer           // the standard error
+	FS     fs.FS               // the files
+}
+
+// A Command is the main function of a program. It returns the exit code.
+type Command func(env *Env) int
+
+// A Case holds the input of a Command and its expected results.
+type Case struct {
+	Args   []string          // the arguments, including the program name
+	Env    map[string]string // the environment variables
+	Stdin  string            // the standard input
+	Files  map[string]string // the contents of the files, by name
+	Stdout string            // the expected standard output
+	Stderr string            // the expected standard error
+	Code   int               // the expected exit code
+}
+
+// A Result holds what a Command wrote and its exit code.
+type Result struct {
+	Stdout string
+	Stderr string
+	Code   int
+}
+
+// Run runs cmd with the input of c and returns its results.
+func (c *Case) Run(cmd Command) Result {
+	var stdout, stderr strings.Builder
+	fsys := fstest.MapFS{}
+	for name, data := range c.Files {
+		fsys[name] = &fstest.MapFile{Data: []byte(data), Mode: 0644}
+	}
+	env := &Env{
+		Args:   c.Args,
+		Getenv: func(name string) string { return c.Env[name] },
+		Stdin:  strings.NewReader(c.Stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		FS:     fsys,
+	}
+	code := cmd(env)
+	return Result{stdout.String(), stderr.String(), code}
+}
+
+// Check runs cmd for each case and reports the results which differ from
+// the expected ones.
+func Check(t *testing.T, cmd Command, cases []Case) {
+	t.Helper()
+	for _, c := range cases {
+		r := c.Run(cmd)
+		if r.Stdout != c.Stdout {
+			t.Errorf("%q: stdout is\n%s\nnot\n%s", c.Args, r.Stdout, c.Stdout)
+		}
+		if r.Stderr != c.Stderr {
+			t.Errorf("%q: stderr is\n%s\nnot\n%s", c.Args, r.Stderr, c.Stderr)
+		}
+		if r.Code != c.Code {
+			t.Errorf("%q: exit code is %d, not %d", c.Args, r.Code, c.Code)
+		}
+	}
+}
+
+// ParseScript returns the Case described by the txtar archive a. The files
+// of the archive are:
+//   - "args": the arguments, one per line;
+//   - "env": the environment variables, as NAME=value lines;
+//   - "stdin": the standard input;
+//   - "stdout", "stderr": the expected output;
+//   - "code": the expected exit code, 0 if missing;
+//   - "fs/NAME": the file NAME.
+func ParseScript(a *Archive) (*Case, error) {
+	c := &Case{Env: map[string]string{}, Files: map[string]string{}}
+	for _, f := range a.Files {
+		switch {
+		case f.Name == "args":
+			c.Args = strings.Split(strings.TrimSuffix(f.Data, "\n"), "\n")
+		case f.Name == "env":
+			for _, line := range strings.Split(f.Data, "\n") {
+				if i := strings.IndexByte(line, '='); i > 0 {
+					c.Env[line[:i]] = line[i+1:]
+				}
+			}
+		case f.Name == "stdin":
+			c.Stdin = f.Data
+		case f.Name == "stdout":
+			c.Stdout = f.Data
+		case f.Name == "stderr":
+			c.Stderr = f.Data
+		case f.Name == "code":
+			n, e := strconv.Atoi(strings.TrimSpace(f.Data))
+			if e != nil {
+				return nil, e
+			}
+			c.Code = n
+		case strings.HasPrefix(f.Name, "fs/"):
+			c.Files[f.Name[3:]] = f.Data
+		}
+	}
+	return c, nil
+}
+
+// RunScript runs cmd as told by the txtar script in the file name (see
+// ParseScript) and reports the results which differ from the expected ones.
+// As an archive can't hold an output without a final end-of-line, a missing
+// one is ignored. If update is true, the expected results are rewritten
+// instead; it is usually set by an -update flag of the test package.
+func RunScript(t *testing.T, cmd Command, name string, update bool) {
+	t.Helper()
+	data, e := os.ReadFile(name)
+	if e != nil {
+		t.Fatal(e)
+	}
+	a := Parse(data)
+	c, e := ParseScript(a)
+	if e != nil {
+		t.Fatalf("%s: %v", name, e)
+	}
+	r := c.Run(cmd)
+	if update {
+		a.Set("stdout", r.Stdout)
+		a.Set("stderr", r.Stderr)
+		a.Set("code", strconv.Itoa(r.Code))
+		if e := os.WriteFile(name, a.Format(), 0644); e != nil {
+			t.Fatal(e)
+		}
+		return
+	}
+	if eol(r.Stdout) != eol(c.Stdout) {
+		t.Errorf("%s: stdout is\n%s\nnot\n%s", name, r.Stdout, c.Stdout)
+	}
+	if eol(r.Stderr) != eol(c.Stderr) {
+		t.Errorf("%s: stderr is\n%s\nnot\n%s", name, r.Stderr, c.Stderr)
+	}
+	if r.Code != c.Code {
+		t.Errorf("%s: exit code is %d, not %d", name, r.Code, c.Code)
+	}
+}
+
+// RunScripts runs RunScript for each file matching the pattern, as a
+// subtest named after the file.
+func RunScripts(t *testing.T, cmd Command, pattern string, update bool) {
+	t.Helper()
+	names, e := filepath.Glob(pattern)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for _, name := range names {
+		name := name
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			RunScript(t, cmd, name, update)
+		})
+	}
+}
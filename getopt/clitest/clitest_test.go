@@ -0,0 +1,82 @@
+package clitest
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the scripts")
+
+func cat(env *Env) int {
+	for _, arg := range env.Args[1:] {
+		fmt.Fprintln(env.Stdout, arg)
+	}
+	fmt.Fprintln(env.Stdout, "hello", env.Getenv("NAME"))
+	io.Copy(env.Stdout, env.Stdin)
+	if data, e := fs.ReadFile(env.FS, "data.txt"); e == nil {
+		env.Stdout.Write(data)
+	}
+	fmt.Fprintln(env.Stderr, "cat: done")
+	return 3
+}
+
+func TestArchive(t *testing.T) {
+	text := "comment\n-- a --\nA\n-- b --\n-- not a marker\n"
+	a := Parse([]byte(text))
+	if a.Comment != "comment\n" || len(a.Files) != 2 {
+		t.Fatalf("Parse() returned %+v", a)
+	}
+	if data, _ := a.Get("b"); data != "-- not a marker\n" {
+		t.Errorf("b: %q", data)
+	}
+	if string(a.Format()) != text {
+		t.Errorf("Format() returned %q", a.Format())
+	}
+	a.Set("a", "no end-of-line")
+	a.Set("c", "C\n")
+	want := "comment\n-- a --\nno end-of-line\n-- b --\n-- not a marker\n-- c --\nC\n"
+	if string(a.Format()) != want {
+		t.Errorf("Format() returned %q", a.Format())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	Check(t, cat, []Case{
+		{
+			Args:   []string{"cat", "x"},
+			Env:    map[string]string{"NAME": "you"},
+			Stdin:  "in\n",
+			Stdout: "x\nhello you\nin\n",
+			Stderr: "cat: done\n",
+			Code:   3,
+		},
+	})
+}
+
+func TestRunScripts(t *testing.T) {
+	RunScripts(t, cat, "testdata/*.txtar", *update)
+}
+
+func TestRunScriptUpdate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "echo.txtar")
+	if e := os.WriteFile(name, []byte("-- args --\necho\nx\n"), 0644); e != nil {
+		t.Fatal(e)
+	}
+	echo := func(env *Env) int {
+		fmt.Fprint(env.Stdout, strings.Join(env.Args[1:], " "))
+		return 0
+	}
+	RunScript(t, echo, name, true)
+	data, _ := os.ReadFile(name)
+	want := "-- args --\necho\nx\n-- stdout --\nx\n-- stderr --\n-- code --\n0\n"
+	if string(data) != want {
+		t.Errorf("the updated script is %q, want %q", data, want)
+	}
+	RunScript(t, echo, name, false)
+}
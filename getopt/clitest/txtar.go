@@ -0,0 +1,94 @@
+package clitest
+
+import (
+	"bytes"
+	"strings"
+)
+
+// An Archive is a txtar archive: a comment followed by named files.
+type Archive struct {
+	Comment string
+	Files   []File
+}
+
+// A File is a file of an Archive.
+type File struct {
+	Name string
+	Data string
+}
+
+// marker returns the name in the file marker line "-- name --", and true,
+// or "" and false if line isn't a marker.
+func marker(line string) (string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "-- ") || !strings.HasSuffix(line, " --") ||
+		len(line) < 7 {
+		return "", false
+	}
+	return strings.TrimSpace(line[3 : len(line)-3]), true
+}
+
+// Parse parses the txtar archive in data. Each file starts after a marker
+// line "-- name --" and ends before the next marker line; the text before
+// the first marker is the comment.
+func Parse(data []byte) *Archive {
+	a := new(Archive)
+	current := &a.Comment
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n') + 1
+		if i == 0 {
+			i = len(data)
+		}
+		line := string(data[:i])
+		data = data[i:]
+		if name, ok := marker(line); ok {
+			a.Files = append(a.Files, File{Name: name})
+			current = &a.Files[len(a.Files)-1].Data
+			continue
+		}
+		*current += line
+	}
+	return a
+}
+
+// Format returns the text of the archive. A missing end-of-line is added at
+// the end of the comment and of each file.
+func (a *Archive) Format() []byte {
+	var b bytes.Buffer
+	b.WriteString(eol(a.Comment))
+	for _, f := range a.Files {
+		b.WriteString("-- " + f.Name + " --\n")
+		b.WriteString(eol(f.Data))
+	}
+	return b.Bytes()
+}
+
+// eol returns s followed by an end-of-line, unless s is empty or already
+// ends with one.
+func eol(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
+
+// Get returns the data of the file name, and whether it exists.
+func (a *Archive) Get(name string) (string, bool) {
+	for _, f := range a.Files {
+		if f.Name == name {
+			return f.Data, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the data of the file name, adding the file if needed.
+func (a *Archive) Set(name, data string) {
+	for i := range a.Files {
+		if a.Files[i].Name == name {
+			a.Files[i].Data = data
+			return
+		}
+	}
+	a.Files = append(a.Files, File{name, data})
+}
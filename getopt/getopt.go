@@ -1,10 +1,16 @@
 // Package getopt provides simple command-line argument parsing, similar to
-// the C function getopt described by POSIX. Optional arguments are not
-// supported.
+// the C function getopt described by POSIX, with optional long options.
+// Optional arguments are not supported, but an option may be declared to
+// take a bounded number of argument words.
 package getopt
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -18,47 +24,237 @@ var (
 	// ErrOption is returned when an invalid option is encountered.
 	ErrOption = errors.New("getopt: option not supported")
 	// ErrNoArg is returned when a required option argument is missing.
-	ErrNoArg  = errors.New("getopt: no argument given")
+	ErrNoArg = errors.New("getopt: no argument given")
+	// ErrFewArgs is returned when an option declared with SetArgs is given
+	// less argument words than it requires.
+	ErrFewArgs = errors.New("getopt: too few arguments given")
+	// ErrRequired is returned when a required option wasn't given.
+	ErrRequired = errors.New("getopt: required option missing")
+	// ErrArg is returned when an argument is attached to a long option
+	// which takes none, like in "--verbose=yes".
+	ErrArg = errors.New("getopt: option takes no argument")
 )
 
+// spec holds what is known about an option besides the options string.
+type spec struct {
+	min, max   int         // the bounds of the number of argument words
+	nargs      bool        // whether the bounds were set by SetArgs
+	validators []Validator // the checks done on each argument
+	choices    []string    // the allowed arguments, if restricted
+	alias      rune        // the canonical option, if this is an alias
+	deprecated bool        // whether a warning is written when used
+	hidden     bool        // whether the option is left out of help
+	help       string      // the description of the option
+	value      string      // the default argument
+	hasValue   bool        // whether a default argument was set
+	secret     bool        // whether the argument is not to be shown
+	limit      int64       // the size limit of the indirect arguments
+}
+
+// long holds the declaration of a long option.
+type long struct {
+	name       string // the name, without prefix
+	o          rune   // the option returned by Option
+	deprecated bool   // whether a warning is written when used
+}
+
 // A Parser holds the slice of strings containing the arguments given on the
 // command line (the first one being the program's name), the index of the
 // argument currently processed, the position of the next character to parse,
-// the options string, a boolean telling whether the last option has an
-// argument, a boolean telling whether all options have been parsed.
+// the options string, the last option as typed and its arguments, the
+// option declarations, the required options and those seen, the catalog of
+// the messages, the destination of the warnings, the prompter, the keys of
+// the operands, the sources of the indirect arguments, the standard options,
+// the synopsis, the handling of numbers, the option prefixes, the long
+// options, the handling of the unknown options and the words it skipped,
+// the trace of the parsing, the prefix of the current word and a boolean
+// telling whether all options have been parsed.
 type Parser struct {
-	args     []string // the arguments received by the program (os.Args)
-	optIndex int      // the index in args of the current option(s)
-	optPos   int      // the position in bytes of the option
-	opts     string   // the options definition string
-	hasArg   bool     // whether the current option has an argument
-	done     bool     // whether all options were parsed
+	args     []string       // the arguments received by the program (os.Args)
+	optIndex int            // the index in args of the current option(s)
+	optPos   int            // the position in bytes of the option
+	opts     string         // the options definition string
+	optOpt   rune           // the current option character, as typed
+	optName  string         // the current option, as typed
+	optArgs  []string       // the arguments of the current option
+	optSrcs  []string       // the sources of the indirect arguments
+	specs    map[rune]*spec // the options declared by the methods of Parser
+	required []rune         // the required options, in declaration order
+	seen     map[rune]bool  // the options found on the command line
+	missing  []rune         // the required options not reported yet
+	checked  bool           // whether the missing options were computed
+	messages *Catalog       // the messages shown to the user
+	warnings io.Writer      // where the warnings are written
+	prompter *Prompter      // asks for the missing required options
+	keys     *Keys          // the keys of the key=value operands
+	fsys     fs.FS          // the files read by the indirect arguments
+	stdin    io.Reader      // the input read by the "@-" arguments
+	std      *Standard      // the handling of the standard options
+	synopsis string         // the operands shown by Usage
+	numbers  bool           // whether negative numbers are not options
+	numOpt   rune           // the option taking numbers as argument
+	shorts   string         // the characters introducing short options
+	long     string         // the prefix introducing long options
+	longs    []*long        // the long options
+	prefix   byte           // the first character of the current option
+	lenient  bool           // whether the unknown options are skipped
+	leftover []string       // the operands skipped in lenient mode
+	trace    *Trace         // the steps of the parsing, if recorded
+	done     bool           // whether all options were parsed
+}
+
+// spec returns the declaration of the option o, creating it if needed.
+func (p *Parser) spec(o rune) *spec {
+	if p.specs == nil {
+		p.specs = make(map[rune]*spec)
+	}
+	s, found := p.specs[o]
+	if !found {
+		s = &spec{}
+		p.specs[o] = s
+	}
+	return s
 }
 
 // Args returns a slice of strings containing the arguments that were not
 // processed yet.
 func (p *Parser) Args() []string {
-	i := p.optIndex
-	if p.hasArg {
-		// if there is an option argument, skip it
+	return p.args[p.optIndex:]
+}
+
+// isNumber reports whether the word s is a '-' followed by a number, like
+// "-20" or "-3.5".
+func isNumber(s string) bool {
+	if len(s) < 2 || s[0] != '-' {
+		return false
+	}
+	i := 1
+	if s[i] == '.' && len(s) > 2 {
 		i++
 	}
-	return p.args[i:]
+	if s[i] < '0' || s[i] > '9' {
+		return false
+	}
+	_, e := strconv.ParseFloat(s, 64)
+	return e == nil || errors.Is(e, strconv.ErrRange)
+}
+
+// isOption reports whether the word s would be parsed as an option (or as
+// the "--" terminator).
+func (p *Parser) isOption(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	if s[0] == '-' && p.numbers && isNumber(s) {
+		return false
+	}
+	if p.long != "" && strings.HasPrefix(s, p.long) {
+		return true
+	}
+	return strings.IndexByte(p.shorts, s[0]) >= 0
+}
+
+// takeArgs moves the arguments of the current option o to p.optArgs. The
+// rest of the current word, if any, is the first argument. Then whole words
+// are taken until max arguments are collected. If strict is true, the
+// collection stops before "--" or any word looking like an option.
+// The method returns the number of arguments collected.
+func (p *Parser) takeArgs(o rune, max int, strict bool) int {
+	secret := p.specs[o] != nil && p.specs[o].secret
+	if p.optPos > 0 {
+		p.optArgs = append(p.optArgs, p.args[p.optIndex][p.optPos:])
+		p.record(KindAttached, p.optIndex, p.optPos, len(p.args[p.optIndex]),
+			secret)
+		p.optIndex++
+		p.optPos = 0
+	}
+	for len(p.optArgs) < max && p.optIndex < len(p.args) {
+		s := p.args[p.optIndex]
+		if strict && p.isOption(s) {
+			break
+		}
+		p.optArgs = append(p.optArgs, s)
+		p.record(KindSeparate, p.optIndex, 0, len(s), secret)
+		p.optIndex++
+	}
+	return len(p.optArgs)
 }
 
 // Option returns the next option encountered as a rune and an error value. It
 // returns (EndOption, nil) when a non-option argument is seen or arguments
 // are exhausted. The error is not nil if the option is not valid or its
-// required argument is missing.
+// required arguments are missing. For an unknown long option, the option
+// returned is '?'.
+// When all options are parsed, Option returns each required option that
+// wasn't given, with ErrRequired, before returning EndOption. If a Prompter
+// was set, the user is asked for the argument of the option instead.
+// If SetStandard was called, the standard options and the errors are
+// handled before returning.
 func (p *Parser) Option() (rune, error) {
+	o, e := p.option()
+	if p.std != nil {
+		return p.standard(o, e)
+	}
+	return o, e
+}
+
+// option returns the next option, like Option, without handling the
+// standard options.
+func (p *Parser) option() (rune, error) {
+	for {
+		o, e := p.next()
+		if o == EndOption {
+			return p.nextMissing()
+		}
+		if e == ErrOption {
+			if p.lenient {
+				continue
+			}
+		} else {
+			if p.seen == nil {
+				p.seen = make(map[rune]bool)
+			}
+			p.seen[o] = true
+		}
+		return o, e
+	}
+}
+
+// nextMissing returns the next required option that wasn't given, or
+// EndOption. The required options are not checked in lenient mode.
+func (p *Parser) nextMissing() (rune, error) {
+	if !p.checked {
+		p.checked = true
+		for _, o := range p.required {
+			if !p.seen[o] && !p.lenient {
+				p.missing = append(p.missing, o)
+			}
+		}
+	}
+	if len(p.missing) == 0 {
+		return EndOption, nil
+	}
+	o := p.missing[0]
+	p.missing = p.missing[1:]
+	p.optOpt = o
+	p.optName = p.display(o)
+	p.prefix = p.optName[0]
+	if p.prompter == nil {
+		return o, ErrRequired
+	}
+	return o, p.prompter.ask(p, o)
+}
+
+// next returns the next option, like Option, without skipping the unknown
+// options.
+func (p *Parser) next() (rune, error) {
+	p.optArgs = nil
+	p.optSrcs = nil
 	if p.done {
 		return EndOption, nil
 	}
-	if p.hasArg {
-		// if there is an option argument, skip it
-		p.optIndex++
-		p.optPos = 0
-		p.hasArg = false
+	if p.lenient && p.optPos == 0 {
+		p.skipOperands()
 	}
 	if p.optIndex >= len(p.args) {
 		p.done = true
@@ -66,38 +262,143 @@ func (p *Parser) Option() (rune, error) {
 	}
 	if p.optPos == 0 {
 		s := p.args[p.optIndex]
-		if len(s) <= 1 || s[0] != '-' {
+		if p.numbers && isNumber(s) && p.numOpt != EndOption {
+			p.prefix = '-'
+			p.optOpt = p.numOpt
+			p.optName = s
+			p.optArgs = append(p.optArgs, s[1:])
+			p.record(KindOption, p.optIndex, 0, 1, false)
+			p.record(KindAttached, p.optIndex, 1, len(s), false)
+			p.optIndex++
+			return p.numOpt, p.validate(p.numOpt, s)
+		}
+		if !p.isOption(s) {
+			p.recordOperands()
 			p.done = true
 			return EndOption, nil
 		}
-		if len(s) == 2 && s[1] == '-' {
+		if s == "--" && strings.IndexByte(p.shorts, '-') >= 0 {
+			p.record(KindTerminator, p.optIndex, 0, 2, false)
 			p.optIndex++
+			p.recordOperands()
 			p.done = true
 			return EndOption, nil
 		}
+		p.prefix = s[0]
+		if p.long != "" && strings.HasPrefix(s, p.long) {
+			name := s[len(p.long):]
+			i := strings.IndexByte(name, '=')
+			if i >= 0 {
+				name = name[:i]
+			}
+			l := p.findLong(name)
+			if l != nil || !p.fallback(s) {
+				return p.longOption(l, name, i)
+			}
+		}
 		p.optPos = 1
 	}
 	b := p.args[p.optIndex][p.optPos]
+	p.optOpt = rune(b)
+	p.optName = string(p.prefix) + string(b)
+	p.record(KindOption, p.optIndex, p.optPos, p.optPos+1, false)
 	p.optPos++
 	if p.optPos >= len(p.args[p.optIndex]) {
 		p.optIndex++
 		p.optPos = 0
 	}
-	if b <= 0x20 || b == ':' || b == '-' || b >= 0x7f {
+	if b <= 0x20 || b == ':' || b == '-' || b >= 0x7f ||
+		strings.IndexByte(p.shorts, b) >= 0 {
 		return rune(b), ErrOption
 	}
-	i := strings.IndexByte(p.opts, b)
-	if i < 0 {
-		return rune(b), ErrOption
+	o := rune(b)
+	if s, found := p.specs[o]; found && s.alias != 0 {
+		if s.deprecated {
+			p.warn(p.optName, p.display(s.alias))
+		}
+		o = s.alias
+		if p.hasLong(o) {
+			return p.arguments(o)
+		}
+	}
+	if strings.IndexRune(p.opts, o) < 0 {
+		return o, ErrOption
 	}
-	i++
-	if i < len(p.opts) && p.opts[i] == ':' {
-		if p.optIndex >= len(p.args) {
-			return rune(b), ErrNoArg
+	return p.arguments(o)
+}
+
+// skipOperands moves the words which are not options, from the current
+// one, to the leftovers. It is used in lenient mode, where the separate
+// argument of an unknown option can't be told from an operand.
+func (p *Parser) skipOperands() {
+	for p.optIndex < len(p.args) {
+		s := p.args[p.optIndex]
+		if p.isOption(s) || p.numbers && isNumber(s) && p.numOpt != EndOption {
+			return
 		}
-		p.hasArg = true
+		p.leftover = append(p.leftover, s)
+		p.record(KindOperand, p.optIndex, 0, len(s), false)
+		p.optIndex++
 	}
-	return rune(b), nil
+}
+
+// fallback reports whether the word s, starting with the long option
+// prefix, may be parsed as a cluster of short options. This is the case
+// when the long option prefix is a single short option prefix.
+func (p *Parser) fallback(s string) bool {
+	return len(p.long) == 1 && strings.IndexByte(p.shorts, p.long[0]) >= 0
+}
+
+// longOption returns the long option l, found as name in the current word.
+// If eq isn't negative, the argument starts after the '=' at the position eq
+// of name. If l is nil, the option is not supported.
+func (p *Parser) longOption(l *long, name string, eq int) (rune, error) {
+	p.optOpt = 0
+	p.optName = p.long + name
+	p.record(KindOption, p.optIndex, 0, len(p.optName), false)
+	if l == nil {
+		p.optIndex++
+		return '?', ErrOption
+	}
+	if l.deprecated {
+		p.warn(p.optName, p.display(l.o))
+	}
+	if eq < 0 {
+		p.optIndex++
+		return p.arguments(l.o)
+	}
+	if !p.takesArgs(l.o) {
+		p.optIndex++
+		return l.o, ErrArg
+	}
+	p.optPos = len(p.long) + eq + 1
+	return p.arguments(l.o)
+}
+
+// takesArgs reports whether the option o takes arguments.
+func (p *Parser) takesArgs(o rune) bool {
+	if s, found := p.specs[o]; found && s.nargs {
+		return s.max > 0
+	}
+	i := strings.IndexRune(p.opts, o)
+	return i >= 0 && i+1 < len(p.opts) && p.opts[i+1] == ':'
+}
+
+// arguments collects and checks the arguments of the option o.
+func (p *Parser) arguments(o rune) (rune, error) {
+	if s, found := p.specs[o]; found && s.nargs {
+		if s.max > 0 && p.takeArgs(o, s.max, true) < s.min {
+			return o, ErrFewArgs
+		}
+	} else if p.takesArgs(o) {
+		if p.takeArgs(o, 1, false) < 1 {
+			return o, ErrNoArg
+		}
+	}
+	if e := p.indirect(o); e != nil {
+		return o, e
+	}
+	return o, p.validate(o, p.optName)
 }
 
 // NewParser returns a pointer to a Parser initialized with the given args
@@ -106,14 +407,342 @@ func (p *Parser) Option() (rune, error) {
 // The opts string has the same format as the one used by the C function
 // getopt described by POSIX. Optional arguments are not supported.
 func NewParser(args []string, opts string) *Parser {
-	return &Parser{args, 1, 0, opts, false, false}
+	return &Parser{args: args, optIndex: 1, opts: opts, shorts: "-", long: "--"}
+}
+
+// Reset rewinds the parser to the first argument, keeping the options
+// string and the option declarations. It allows parsing the arguments
+// several times, for example after a first pass looking only for the name
+// of a configuration file.
+func (p *Parser) Reset() {
+	p.optIndex = 1
+	p.optPos = 0
+	p.optOpt = 0
+	p.optName = ""
+	p.optArgs = nil
+	p.optSrcs = nil
+	p.prefix = 0
+	p.seen = nil
+	p.missing = nil
+	p.checked = false
+	p.done = false
+	p.leftover = nil
+	if p.trace != nil {
+		p.trace = &Trace{}
+	}
+	if p.std != nil {
+		p.std.debug = false
+	}
+}
+
+// ResetArgs rewinds the parser like Reset and makes it parse args instead
+// of its arguments. The first item of args is skipped, like by NewParser.
+// With Clone, it allows parsing several command lines with the same option
+// declarations.
+func (p *Parser) ResetArgs(args []string) {
+	p.args = args
+	p.Reset()
+}
+
+// Clone returns a copy of the parser, in the same state. The option
+// declarations, the standard options and the prompter of the copy may be
+// changed without altering the original. The prompter of the copy reads
+// from the same input.
+func (p *Parser) Clone() *Parser {
+	q := *p
+	q.optArgs = append([]string(nil), p.optArgs...)
+	q.optSrcs = append([]string(nil), p.optSrcs...)
+	q.required = append([]rune(nil), p.required...)
+	q.longs = make([]*long, len(p.longs))
+	for i, l := range p.longs {
+		c := *l
+		q.longs[i] = &c
+	}
+	q.missing = append([]rune(nil), p.missing...)
+	q.leftover = append([]string(nil), p.leftover...)
+	if p.trace != nil {
+		q.trace = &Trace{append([]Step(nil), p.trace.Steps...)}
+	}
+	if p.std != nil {
+		s := *p.std
+		q.std = &s
+	}
+	if p.prompter != nil {
+		c := *p.prompter
+		q.prompter = &c
+	}
+	if p.seen != nil {
+		q.seen = make(map[rune]bool, len(p.seen))
+		for o := range p.seen {
+			q.seen[o] = true
+		}
+	}
+	if p.specs != nil {
+		q.specs = make(map[rune]*spec, len(p.specs))
+		for o, s := range p.specs {
+			c := *s
+			c.validators = append([]Validator(nil), s.validators...)
+			q.specs[o] = &c
+		}
+	}
+	return &q
+}
+
+// SetLenient makes Option skip the unknown options instead of returning
+// ErrOption. This is meant for a first pass looking for a few options,
+// before the full pass done after Reset. The words which are not options
+// don't end the options in lenient mode: they are skipped too, so that an
+// unknown option may be followed by its argument in a separate word, and
+// Leftovers returns them. Only "--" ends the options.
+func (p *Parser) SetLenient(lenient bool) {
+	p.lenient = lenient
+}
+
+// Leftovers returns the words which are not options skipped in lenient
+// mode, like the operands and the separate arguments of unknown options,
+// in the order of the arguments. The words after "--" are returned by Args.
+func (p *Parser) Leftovers() []string {
+	return p.leftover
+}
+
+// SetArgs declares that the option o, which must be present in the options
+// string, takes at least min and at most max argument words. The first
+// argument may follow the option letter in the same word, like in "-r1";
+// the others are always separate words. The collection of arguments stops
+// before "--" or before the next word that looks like an option. If less
+// than min arguments are found, Option returns ErrFewArgs.
+// A negative bound is taken as 0 and a max lesser than min is taken as min.
+// SetArgs overrides the ':' that may follow o in the options string.
+func (p *Parser) SetArgs(o rune, min, max int) {
+	if min < 0 {
+		min = 0
+	}
+	if max < min {
+		max = min
+	}
+	s := p.spec(o)
+	s.min, s.max, s.nargs = min, max, true
+}
+
+// Require declares the option o as required. See Option.
+func (p *Parser) Require(o rune) {
+	p.required = append(p.required, o)
+}
+
+// SetHelp sets the description of the option o. A word of text enclosed in
+// back quotes is taken as the name of the argument, as in the flag package.
+func (p *Parser) SetHelp(o rune, text string) {
+	p.spec(o).help = text
+}
+
+// SetDefault sets the default argument of the option o. It is offered by the
+// Prompter.
+func (p *Parser) SetDefault(o rune, value string) {
+	s := p.spec(o)
+	s.value, s.hasValue = value, true
+}
+
+// SetSecret marks the arguments of the option o as secret. The Prompter
+// doesn't echo them.
+func (p *Parser) SetSecret(o rune) {
+	p.spec(o).secret = true
+}
+
+// SetNumeric makes the parser recognize the words made of a '-' followed by
+// a number, like "-20" or "-3.5". Such words are never options, even if the
+// options string holds digits, and they may be given as arguments to the
+// options declared with SetArgs.
+// If o is EndOption, the numbers are operands: like any other operand, they
+// end the parsing of options. Otherwise, Option returns o for such a word
+// and the number without its '-' becomes the argument of o, like in head's
+// "-20" meaning "-n 20". In this case, o must be in the options string or
+// declared with SetArgs before; otherwise, nothing is changed and the error
+// wraps ErrOption.
+func (p *Parser) SetNumeric(o rune) error {
+	if o != EndOption && strings.IndexRune(p.opts, o) < 0 &&
+		(p.specs[o] == nil || !p.specs[o].nargs) {
+		return fmt.Errorf("%w: %s", ErrOption, p.display(o))
+	}
+	p.numbers = true
+	p.numOpt = o
+	return nil
+}
+
+// SetPlus makes the parser accept the words starting with '+' as options,
+// like in "set +x". They follow the same rules as the words starting with
+// '-', but "++" doesn't end the options. OptPrefix tells which prefix was
+// used, so that "+abc" may turn off what "-abc" turns on.
+// It is the same as adding '+' to the short option prefixes.
+func (p *Parser) SetPlus(plus bool) {
+	p.shorts = strings.ReplaceAll(p.shorts, "+", "")
+	if plus {
+		p.shorts += "+"
+	}
+}
+
+// OptPrefix returns the first character of the last option returned by
+// Option: '-' or, if enabled by SetPlus or SetPrefixes, another character
+// introducing options.
+func (p *Parser) OptPrefix() rune {
+	return rune(p.prefix)
+}
+
+// SetPrefixes sets the characters introducing the clusters of short options
+// and the prefix introducing the long options. The default is "-" and "--".
+// An empty long prefix disables the long options. The "--" terminator ends
+// the options only while '-' introduces short options.
+// When the long prefix is one of the short option characters, a word made
+// of the prefix and an unknown name is parsed as a cluster of short options.
+// This allows single-dash long options, like Java's "-classpath", along with
+// the usual "-xvf". For slash-style switches, the prefixes would be "/" and
+// "/", or "-/" and "/".
+func (p *Parser) SetPrefixes(shorts, long string) {
+	p.shorts, p.long = shorts, long
+	if shorts == "" && p.std != nil && p.std.short {
+		// drop the "-h" added by SetStandard
+		p.std.short = false
+		p.opts = strings.Replace(p.opts, "h", "", 1)
+	}
+}
+
+// Long declares name as the long option for o. When the long option prefix
+// followed by name is found on the command line, Option returns o. The
+// argument may follow name after a '=' in the same word, or be given in the
+// next word. The option takes arguments if o is followed by ':' in the
+// options string or if it was declared with SetArgs. The option o doesn't
+// need to be in the options string. Several names may be given to the same
+// option; OptName returns the one actually used.
+func (p *Parser) Long(name string, o rune) {
+	if l := p.findLong(name); l != nil {
+		l.o = o
+		return
+	}
+	p.longs = append(p.longs, &long{name: name, o: o})
+}
+
+// DeprecateLong marks the long option name as deprecated. It is still
+// parsed, but every use writes a warning (see SetWarnings).
+func (p *Parser) DeprecateLong(name string) {
+	if l := p.findLong(name); l != nil {
+		l.deprecated = true
+	}
+}
+
+// hasLong reports whether the option o has a long option.
+func (p *Parser) hasLong(o rune) bool {
+	for _, l := range p.longs {
+		if l.o == o {
+			return true
+		}
+	}
+	return false
+}
+
+// findLong returns the long option called name, or nil.
+func (p *Parser) findLong(name string) *long {
+	for _, l := range p.longs {
+		if l.name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// display returns the usual spelling of the option o: the short option if
+// o is in the options string, else its first long option which is not
+// deprecated.
+func (p *Parser) display(o rune) string {
+	if o < 0x7f && strings.IndexRune(p.opts, o) >= 0 && p.shorts != "" {
+		return string(p.shorts[0]) + string(o)
+	}
+	for _, l := range p.longs {
+		if l.o == o && !l.deprecated {
+			return p.long + l.name
+		}
+	}
+	return "-" + string(o)
+}
+
+// Alias declares the option letter alias as another spelling of the option
+// o, which must be present in the options string or declared by Long. When
+// alias is found on the command line, Option returns o. OptName returns the
+// spelling actually used.
+func (p *Parser) Alias(alias, o rune) {
+	p.spec(alias).alias = o
+}
+
+// Deprecate marks the alias as deprecated. It is still parsed, but every use
+// writes a warning (see SetWarnings).
+func (p *Parser) Deprecate(alias rune) {
+	p.spec(alias).deprecated = true
+}
+
+// Hide marks the option o as hidden. A hidden option is parsed as usual,
+// but it is left out of the help text and of the completion of a Shell.
+func (p *Parser) Hide(o rune) {
+	p.spec(o).hidden = true
+}
+
+// SetWarnings sets the destination of the warnings written by the parser.
+// The default is os.Stderr. If w is nil, the warnings are discarded.
+func (p *Parser) SetWarnings(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	p.warnings = w
+}
+
+// warn writes the warning about the use of the deprecated alias of o, both
+// given as spelled on the command line.
+func (p *Parser) warn(alias, o string) {
+	w := p.warnings
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "getopt: %s\n", p.catalog().Message("option.deprecated", alias, o))
+}
+
+// OptName returns the last option returned by Option as it was typed on
+// the command line, like "-x". It differs from the option returned when an
+// alias was used.
+func (p *Parser) OptName() string {
+	return p.optName
+}
+
+// OptInd returns the index in args of the next argument to be processed,
+// like the variable optind of the C function getopt. When the last option
+// is not the end of its word, it's the index of that word.
+func (p *Parser) OptInd() int {
+	return p.optIndex
+}
+
+// OptPos returns the position in bytes, in the word at OptInd, of the next
+// option character to be parsed. It's 0 when the next option starts a new
+// word.
+func (p *Parser) OptPos() int {
+	return p.optPos
+}
+
+// OptOpt returns the last option character found on the command line, as
+// typed, like the variable optopt of the C function getopt. It's the option
+// responsible for the error returned by Option, if any. It's 0 for a long
+// option.
+func (p *Parser) OptOpt() rune {
+	return p.optOpt
 }
 
 // OptArg returns the argument of the last option returned by Option, or the
-// empty string if none was given.
+// empty string if none was given. For an option declared with SetArgs, it
+// returns the first argument.
 func (p *Parser) OptArg() string {
-	if !p.hasArg {
+	if len(p.optArgs) == 0 {
 		return ""
 	}
-	return p.args[p.optIndex][p.optPos:]
+	return p.optArgs[0]
+}
+
+// OptArgs returns all the arguments of the last option returned by Option.
+// The slice is empty if the option has no arguments.
+func (p *Parser) OptArgs() []string {
+	return p.optArgs
 }
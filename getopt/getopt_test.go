@@ -1,24 +1,103 @@
 package getopt
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/vtudorache/go-utils/getopt/clitest"
 )
 
-var aFlag = false
-var bOption = ""
+var update = flag.Bool("update", false, "rewrite the golden files of the scripts")
+
+// tool is the command used by the table-driven tests and the scripts. It
+// prints the options found in its arguments, one per line, then the
+// operands.
+func tool(env *clitest.Env) int {
+	code, exited := 0, false
+	p := NewParser(env.Args, "ab:")
+	c, _ := LoadCatalog(messages, "messages/getopt", Locale(env.Getenv))
+	p.SetCatalog(c)
+	p.Long("bee", 'b')
+	p.SetHelp('a', "set the flag a")
+	p.SetHelp('b', "set the option b to `value`")
+	p.SetStandard(Standard{
+		Stdout: env.Stdout,
+		Stderr: env.Stderr,
+		Exit:   func(c int) { code, exited = c, true },
+	})
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e == nil {
+			fmt.Fprintf(env.Stdout, "%s %q\n", p.OptName(), p.OptArg())
+		}
+	}
+	if !exited {
+		fmt.Fprintf(env.Stdout, "operands %q\n", p.Args())
+	}
+	return code
+}
 
 func TestOptions(t *testing.T) {
-	args := []string{"test", "-ab", "cdef"}
-	opts := "ab:"
-	p := NewParser(args, opts)
+	clitest.Check(t, tool, []clitest.Case{
+		{
+			Args:   []string{"test", "-ab", "cdef"},
+			Stdout: "-a \"\"\n-b \"cdef\"\noperands []\n",
+		},
+		{
+			Args:   []string{"test", "-a", "-bcdef", "--", "-a"},
+			Stdout: "-a \"\"\n-b \"cdef\"\noperands [\"-a\"]\n",
+		},
+		{
+			Args:   []string{"test", "-ab"},
+			Env:    map[string]string{"LANG": "C"},
+			Stdout: "-a \"\"\n",
+			Stderr: "test: option -b requires an argument\n" +
+				"Try 'test --help' for more information.\n",
+			Code: 2,
+		},
+	})
+}
+
+func TestBadOptions(t *testing.T) {
+	clitest.Check(t, tool, []clitest.Case{
+		{
+			Args:   []string{"test", "-ab", "cdef", "-x"},
+			Env:    map[string]string{"LANG": "C"},
+			Stdout: "-a \"\"\n-b \"cdef\"\n",
+			Stderr: "test: option -x not supported\n" +
+				"Try 'test --help' for more information.\n",
+			Code: 2,
+		},
+	})
+}
+
+func TestScripts(t *testing.T) {
+	clitest.RunScripts(t, tool, "testdata/*.txtar", *update)
+}
+
+func TestArgs(t *testing.T) {
+	args := []string{"test", "-p", "1", "2", "-r3", "4", "5", "-a", "file"}
+	p := NewParser(args, "ap:r:")
+	p.SetArgs('p', 2, 2)
+	p.SetArgs('r', 1, 2)
 	end := false
 	for !end {
 		switch o, e := p.Option(); o {
 		case 'a':
-			aFlag = true
-		case 'b':
-			bOption = p.OptArg()
+		case 'p':
+			if strings.Join(p.OptArgs(), ",") != "1,2" {
+				t.Errorf("-p: OptArgs() returned %q", p.OptArgs())
+			}
+		case 'r':
+			if strings.Join(p.OptArgs(), ",") != "3,4" {
+				t.Errorf("-r: OptArgs() returned %q", p.OptArgs())
+			}
 		default:
 			if e != nil {
 				t.Errorf("%s: -%c", e, o)
@@ -26,29 +105,28 @@ func TestOptions(t *testing.T) {
 			end = true
 		}
 	}
-	if !aFlag {
-		t.Error("aFlag should have been set")
-	}
-	if strings.Compare(bOption, "cdef") != 0 {
-		t.Error("bOption should have been \"cdef\"")
+	if strings.Join(p.Args(), ",") != "5,-a,file" {
+		t.Errorf("Args() returned %q", p.Args())
 	}
 }
 
-func TestBadOptions(t *testing.T) {
-	args := []string{"test", "-ab", "cdef", "-x"}
-	opts := "ab:"
-	p := NewParser(args, opts)
+func TestFewArgs(t *testing.T) {
+	args := []string{"test", "-p", "1", "-a", "-p", "1", "--", "file"}
+	p := NewParser(args, "ap:")
+	p.SetArgs('p', 2, 3)
+	count := 0
 	end := false
 	for !end {
 		switch o, e := p.Option(); o {
 		case 'a':
-			aFlag = true
-		case 'b':
-			bOption = p.OptArg()
-		case 'x':
-			if e == nil {
-				t.Error("option should not be defined: -x")
+		case 'p':
+			if e != ErrFewArgs {
+				t.Errorf("-p: Option() returned %v, not ErrFewArgs", e)
 			}
+			if p.OptArg() != "1" {
+				t.Errorf("-p: OptArg() returned %q", p.OptArg())
+			}
+			count++
 		default:
 			if e != nil {
 				t.Errorf("%s: -%c", e, o)
@@ -56,10 +134,286 @@ func TestBadOptions(t *testing.T) {
 			end = true
 		}
 	}
-	if !aFlag {
-		t.Error("aFlag should have been set")
+	if count != 2 {
+		t.Errorf("-p seen %d times, not 2", count)
+	}
+	if strings.Join(p.Args(), ",") != "file" {
+		t.Errorf("Args() returned %q", p.Args())
+	}
+}
+
+func TestAlias(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	var b strings.Builder
+	args := []string{"test", "-vqV", "-o", "out"}
+	p := NewParser(args, "vf:")
+	p.Alias('V', 'v')
+	p.Alias('q', 'v')
+	p.Alias('o', 'f')
+	p.Deprecate('o')
+	p.Hide('q')
+	p.SetWarnings(&b)
+	var names []string
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			t.Errorf("%s: %s", e, p.OptName())
+		}
+		if o == 'f' && p.OptArg() != "out" {
+			t.Errorf("-f: OptArg() returned %q", p.OptArg())
+		}
+		names = append(names, string(o)+p.OptName())
+	}
+	if strings.Join(names, ",") != "v-v,v-q,v-V,f-o" {
+		t.Errorf("options returned: %q", names)
+	}
+	if b.String() != "getopt: option -o is deprecated, use -f instead\n" {
+		t.Errorf("warnings: %q", b.String())
+	}
+	if u := p.Usage(); !strings.Contains(u, "  -v, -V\n") || strings.Contains(u, "-q") {
+		t.Errorf("Usage() returned\n%s", u)
+	}
+	p = NewParser([]string{"test", "-dL", "x"}, "")
+	p.Long("dry-run", -10)
+	p.Long("level", 'λ')
+	p.SetArgs('λ', 1, 1)
+	p.Alias('d', -10)
+	p.Alias('L', 'λ')
+	if o, e := p.Option(); o != -10 || e != nil {
+		t.Errorf("-d: Option() returned %d, %v", o, e)
+	}
+	if o, e := p.Option(); o != 'λ' || e != nil || p.OptArg() != "x" {
+		t.Errorf("-L: Option() returned %q, %v, %q", o, e, p.OptArg())
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	args := []string{"test", "-20", "-n5", "-p", "-1.5", "-2", "-v", "-3.5", "file"}
+	p := NewParser(args, "n:p:v")
+	p.SetNumeric('n')
+	p.SetArgs('p', 2, 2)
+	var seen []string
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			t.Errorf("%s: %s", e, p.OptName())
+		}
+		seen = append(seen, string(o)+strings.Join(p.OptArgs(), ","))
+	}
+	if strings.Join(seen, " ") != "n20 n5 p-1.5,-2 v n3.5" {
+		t.Errorf("options returned: %q", seen)
+	}
+	p = NewParser([]string{"test", "-v", "-3.5", "-x"}, "v")
+	p.SetNumeric(EndOption)
+	if o, e := p.Option(); o != 'v' || e != nil {
+		t.Errorf("Option() returned %c, %v", o, e)
+	}
+	if o, e := p.Option(); o != EndOption || e != nil {
+		t.Errorf("Option() returned %c, %v", o, e)
+	}
+	if strings.Join(p.Args(), " ") != "-3.5 -x" {
+		t.Errorf("Args() returned %q", p.Args())
+	}
+	p = NewParser([]string{"test", "-5"}, "v")
+	if e := p.SetNumeric('n'); !errors.Is(e, ErrOption) {
+		t.Errorf("SetNumeric('n') returned %v", e)
+	}
+	if o, e := p.Option(); o != '5' || e != ErrOption {
+		t.Errorf("Option() returned %c, %v", o, e)
+	}
+	p.SetArgs('m', 1, 1)
+	if e := p.SetNumeric('m'); e != nil {
+		t.Errorf("SetNumeric('m') returned %v", e)
+	}
+}
+
+func TestPlus(t *testing.T) {
+	args := []string{"test", "-ab", "+ba", "+o", "vi", "+", "-c"}
+	p := NewParser(args, "abco:")
+	p.SetPlus(true)
+	flags := map[rune]bool{}
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			t.Errorf("%s: %s", e, p.OptName())
+		}
+		flags[o] = p.OptPrefix() == '-'
+		if o == 'o' && (p.OptName() != "+o" || p.OptArg() != "vi") {
+			t.Errorf("%s: OptArg() returned %q", p.OptName(), p.OptArg())
+		}
+	}
+	if flags['a'] || flags['b'] || flags['o'] {
+		t.Errorf("flags: %v", flags)
+	}
+	if strings.Join(p.Args(), " ") != "+ -c" {
+		t.Errorf("Args() returned %q", p.Args())
+	}
+	p = NewParser(args, "ab")
+	if o, e := p.Option(); o != 'a' || e != nil {
+		t.Errorf("Option() returned %c, %v", o, e)
+	}
+	p.Option()
+	if o, _ := p.Option(); o != EndOption {
+		t.Errorf("+ba parsed as an option without SetPlus")
+	}
+}
+
+func TestState(t *testing.T) {
+	args := []string{"test", "-xc", "app.conf", "-vy", "file"}
+	p := NewParser(args, "c:v")
+	if o, e := p.Option(); o != 'x' || e != ErrOption || p.OptOpt() != 'x' {
+		t.Errorf("Option() returned %c, %v", o, e)
+	}
+	if p.OptInd() != 1 || p.OptPos() != 2 {
+		t.Errorf("OptInd() = %d, OptPos() = %d", p.OptInd(), p.OptPos())
+	}
+	q := p.Clone()
+	q.Option()
+	if q.OptInd() != 3 || p.OptInd() != 1 {
+		t.Errorf("OptInd() = %d for clone, %d for parser", q.OptInd(), p.OptInd())
+	}
+	p.Reset()
+	p.SetLenient(true)
+	config := ""
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			t.Errorf("%s: %s", e, p.OptName())
+		}
+		if o == 'c' {
+			config = p.OptArg()
+		}
+	}
+	if config != "app.conf" || p.OptInd() != 5 {
+		t.Errorf("first pass: config = %q, OptInd() = %d", config, p.OptInd())
+	}
+	if left := p.Leftovers(); len(left) != 1 || left[0] != "file" {
+		t.Errorf("first pass: Leftovers() returned %q", left)
+	}
+	p.Reset()
+	p.SetLenient(false)
+	errs := 0
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			errs++
+		}
+	}
+	if errs != 2 {
+		t.Errorf("second pass: %d errors, not 2", errs)
+	}
+}
+
+func TestLenient(t *testing.T) {
+	args := []string{"test", "--log-level", "debug", "--config", "a.conf", "in",
+		"-q", "--", "-c", "x"}
+	p := NewParser(args, "c:")
+	p.Long("config", 'c')
+	p.SetLenient(true)
+	config := ""
+	for o, e := p.Option(); o != EndOption; o, e = p.Option() {
+		if e != nil {
+			t.Errorf("%s: %s", e, p.OptName())
+		}
+		if o == 'c' {
+			config = p.OptArg()
+		}
+	}
+	if config != "a.conf" {
+		t.Errorf("config = %q", config)
+	}
+	if s := strings.Join(p.Leftovers(), " "); s != "debug in" {
+		t.Errorf("Leftovers() returned %q", s)
 	}
-	if strings.Compare(bOption, "cdef") != 0 {
-		t.Error("bOption should have been \"cdef\"")
+	if s := strings.Join(p.Args(), " "); s != "-c x" {
+		t.Errorf("Args() returned %q", s)
+	}
+	p.Reset()
+	if p.Leftovers() != nil {
+		t.Errorf("Reset kept the leftovers %q", p.Leftovers())
+	}
+}
+
+func TestLong(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	var b strings.Builder
+	args := []string{"test", "--verbose", "--output=out", "-vo", "file", "--point", "1", "2",
+		"--colour", "--verbose=yes", "--bad", "--", "--verbose"}
+	p := NewParser(args, "vo:")
+	p.Long("verbose", 'v')
+	p.Long("output", 'o')
+	p.Long("point", 'p')
+	p.SetArgs('p', 2, 2)
+	p.Long("color", 'c')
+	p.Long("colour", 'c')
+	p.DeprecateLong("colour")
+	p.SetWarnings(&b)
+	var seen []string
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		s := string(o) + p.OptName() + strings.Join(p.OptArgs(), ",")
+		if e != nil {
+			s += "!"
+		}
+		seen = append(seen, s)
 	}
-}
\ No newline at end of file
+	if strings.Join(seen, " ") != "v--verbose o--outputout v-v o-ofile p--point1,2 "+
+		"c--colour v--verbose! ?--bad!" {
+		t.Errorf("options returned: %q", seen)
+	}
+	if b.String() != "getopt: option --colour is deprecated, use --color instead\n" {
+		t.Errorf("warnings: %q", b.String())
+	}
+	if strings.Join(p.Args(), ",") != "--verbose" {
+		t.Errorf("Args() returned %q", p.Args())
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	tests := []struct {
+		shorts, long string
+		args         []string
+		seen         string
+	}{
+		{"-", "-", []string{"-classpath", "lib", "-cv", "-verbose"}, "c-classpathlib c-cv v-verbose"},
+		{"/", "/", []string{"/out=x", "/cv", "-v"}, "o/outx c/cv"},
+		{"-/", "/", []string{"-v", "/out", "x", "/v"}, "v-v o/outx v/v"},
+		{"-", "", []string{"-v", "--out", "x"}, "v-v"},
+	}
+	for _, test := range tests {
+		p := NewParser(append([]string{"test"}, test.args...), "c:vo:")
+		p.SetPrefixes(test.shorts, test.long)
+		p.Long("classpath", 'c')
+		p.Long("verbose", 'v')
+		p.Long("out", 'o')
+		var seen []string
+		for {
+			o, e := p.Option()
+			if o == EndOption || e != nil {
+				break
+			}
+			seen = append(seen, string(o)+p.OptName()+p.OptArg())
+		}
+		if strings.Join(seen, " ") != test.seen {
+			t.Errorf("%q %q: options returned %q", test.shorts, test.long, seen)
+		}
+	}
+}
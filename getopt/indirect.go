@@ -0,0 +1,98 @@
+package getopt
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// DefaultLimit is the size limit of the indirect arguments, used when
+// SetIndirect is given a limit lesser than 1.
+const DefaultLimit = 64 << 10
+
+// ErrSize is returned when an indirect argument is larger than its limit.
+var ErrSize = errors.New("getopt: value too large")
+
+// SetIndirect allows the arguments of the option o to be read from a file or
+// from the standard input, so that secrets don't show in the list of
+// processes. An argument "@name" is replaced by the contents of the file
+// name, and "@-" by the contents of the standard input. The trailing
+// end-of-lines are removed. An argument starting with "@@" is given with
+// its first '@' removed. The contents may be at most limit bytes long.
+// Reading errors are reported as a *ValueError. Indirect arguments are
+// read before being checked by the validators of o.
+func (p *Parser) SetIndirect(o rune, limit int64) {
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+	p.spec(o).limit = limit
+}
+
+// SetFiles sets the file system and the standard input used to read the
+// indirect arguments. The names are cleaned and their leading slashes are
+// removed before being looked up in fsys. If fsys is nil, the files are
+// opened by os.Open; if stdin is nil, os.Stdin is read.
+func (p *Parser) SetFiles(fsys fs.FS, stdin io.Reader) {
+	p.fsys, p.stdin = fsys, stdin
+}
+
+// OptSource returns the source of the argument returned by OptArg: the
+// indirect argument as given on the command line, like "@-" or
+// "@/run/secrets/password", or the empty string if the argument was given
+// directly. Unlike OptArg, it can be shown to the user.
+func (p *Parser) OptSource() string {
+	if len(p.optSrcs) == 0 {
+		return ""
+	}
+	return p.optSrcs[0]
+}
+
+// open opens the source of an indirect argument.
+func (p *Parser) open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		if p.stdin == nil {
+			return io.NopCloser(os.Stdin), nil
+		}
+		return io.NopCloser(p.stdin), nil
+	}
+	if p.fsys == nil {
+		return os.Open(name)
+	}
+	return p.fsys.Open(fsName(name))
+}
+
+// indirect replaces the indirect arguments of the option o by their
+// contents.
+func (p *Parser) indirect(o rune) error {
+	s, found := p.specs[o]
+	if !found || s.limit == 0 {
+		return nil
+	}
+	p.optSrcs = make([]string, len(p.optArgs))
+	for i, a := range p.optArgs {
+		if strings.HasPrefix(a, "@@") {
+			p.optArgs[i] = a[1:]
+			continue
+		}
+		if !strings.HasPrefix(a, "@") {
+			continue
+		}
+		p.optSrcs[i] = a
+		f, e := p.open(a[1:])
+		if e != nil {
+			return &ValueError{p.optName, a, e}
+		}
+		b, e := io.ReadAll(io.LimitReader(f, s.limit+1))
+		f.Close()
+		if e == nil && int64(len(b)) > s.limit {
+			e = ErrSize
+		}
+		if e != nil {
+			return &ValueError{p.optName, a, e}
+		}
+		p.optArgs[i] = strings.TrimRight(string(b), "\r\n")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package getopt
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIndirect(t *testing.T) {
+	fsys := fstest.MapFS{
+		"run/secret": {Data: []byte("s3cret\n\n")},
+		"big":        {Data: []byte("0123456789")},
+	}
+	args := []string{"test", "-p@/run/secret", "--token=@-", "-u", "@@me", "-p", "@big",
+		"-p", "@missing", "-u", "@/run/secret"}
+	p := NewParser(args, "p:u:t:")
+	p.Long("token", 't')
+	p.SetIndirect('p', 8)
+	p.SetIndirect('t', 0)
+	p.SetFiles(fsys, strings.NewReader("t0ken\r\n"))
+	p.SetValidator('t', Match(regexp.MustCompile(`[a-z]+`)))
+	var seen []string
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		s := string(o) + "=" + p.OptArg() + "<" + p.OptSource()
+		var v *ValueError
+		if errors.As(e, &v) {
+			if strings.Contains(e.Error(), "t0ken") {
+				t.Errorf("the error shows the secret: %v", e)
+			}
+			s = string(o) + "!" + v.Value
+		}
+		seen = append(seen, s)
+	}
+	want := "p=s3cret<@/run/secret t!@- u=@@me< p!@big p!@missing u=@/run/secret<"
+	if strings.Join(seen, " ") != want {
+		t.Errorf("options returned %q", seen)
+	}
+}
@@ -0,0 +1,209 @@
+package getopt
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A Value is the typed value bound to a key. Set parses and stores the
+// value given on the command line, String returns the current value.
+type Value interface {
+	String() string
+	Set(string) error
+}
+
+// Unknown tells how Keys.Parse handles the keys that were not declared.
+type Unknown int
+
+const (
+	// UnknownError makes Parse return a *ValueError wrapping ErrKey.
+	UnknownError Unknown = iota
+	// UnknownCollect makes Parse keep the pairs, see Keys.Unknown.
+	UnknownCollect
+	// UnknownPass makes Parse return the pairs along with the operands.
+	UnknownPass
+)
+
+// ErrKey is returned when a key is not declared.
+var ErrKey = errors.New("getopt: key not supported")
+
+// key holds the declaration of a key.
+type key struct {
+	value      Value       // where the value is stored
+	help       string      // the description of the key
+	validators []Validator // the checks done on the value
+}
+
+// Keys parses the operands written as key=value, like in "dd if=in.img
+// bs=4M count=10". The keys are declared with a typed value. It is meant
+// to parse the operands left by a Parser, see Parser.Keys.
+type Keys struct {
+	keys    map[string]*key // the declared keys
+	order   []string        // the declared keys, in declaration order
+	unknown Unknown         // the handling of the unknown keys
+	extra   []string        // the collected unknown pairs
+}
+
+// NewKeys returns a pointer to a Keys handling the unknown keys as told by
+// unknown.
+func NewKeys(unknown Unknown) *Keys {
+	return &Keys{keys: make(map[string]*key), unknown: unknown}
+}
+
+// Keys returns a pointer to a Keys attached to the parser. Its keys are
+// documented along with the options. Call its Parse method with the result
+// of Args, once Option returned EndOption.
+func (p *Parser) Keys(unknown Unknown) *Keys {
+	p.keys = NewKeys(unknown)
+	return p.keys
+}
+
+// Var declares the key name, storing its value in v. The text describes
+// the key; a word enclosed in back quotes is the name of the value.
+func (k *Keys) Var(name string, v Value, help string) {
+	if _, found := k.keys[name]; !found {
+		k.order = append(k.order, name)
+	}
+	k.keys[name] = &key{value: v, help: help}
+}
+
+// SetValidator adds validators to the key name. They check the value before
+// it is stored.
+func (k *Keys) SetValidator(name string, v ...Validator) {
+	if d, found := k.keys[name]; found {
+		d.validators = append(d.validators, v...)
+	}
+}
+
+type stringValue string
+
+func (s *stringValue) Set(v string) error {
+	*s = stringValue(v)
+	return nil
+}
+
+func (s *stringValue) String() string {
+	return string(*s)
+}
+
+// String declares the key name holding a string. It returns a pointer to
+// the value, initialized to value.
+func (k *Keys) String(name, value, help string) *string {
+	p := new(string)
+	*p = value
+	k.Var(name, (*stringValue)(p), help)
+	return p
+}
+
+type intValue int64
+
+func (i *intValue) Set(v string) error {
+	n, e := strconv.ParseInt(v, 0, 64)
+	if e != nil {
+		return e.(*strconv.NumError).Err
+	}
+	*i = intValue(n)
+	return nil
+}
+
+func (i *intValue) String() string {
+	return strconv.FormatInt(int64(*i), 10)
+}
+
+// Int declares the key name holding an integer, parsed by strconv.ParseInt
+// with a base given by its prefix. It returns a pointer to the value,
+// initialized to value.
+func (k *Keys) Int(name string, value int64, help string) *int64 {
+	p := new(int64)
+	*p = value
+	k.Var(name, (*intValue)(p), help)
+	return p
+}
+
+// sizes holds the multipliers of the size suffixes, like dd(1).
+var sizes = map[string]int64{
+	"": 1, "c": 1, "w": 2, "b": 512,
+	"K": 1 << 10, "KiB": 1 << 10, "kB": 1e3, "KB": 1e3,
+	"M": 1 << 20, "MiB": 1 << 20, "MB": 1e6,
+	"G": 1 << 30, "GiB": 1 << 30, "GB": 1e9,
+	"T": 1 << 40, "TiB": 1 << 40, "TB": 1e12,
+}
+
+type sizeValue int64
+
+func (s *sizeValue) Set(v string) error {
+	i := strings.IndexFunc(v, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		i = len(v)
+	}
+	m, found := sizes[v[i:]]
+	if i == 0 || !found {
+		return strconv.ErrSyntax
+	}
+	n, e := strconv.ParseInt(v[:i], 10, 64)
+	if e != nil || n > (1<<63-1)/m {
+		return strconv.ErrRange
+	}
+	*s = sizeValue(n * m)
+	return nil
+}
+
+func (s *sizeValue) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+// Size declares the key name holding a number of bytes. The number may be
+// followed by a suffix, as for dd(1): c (1), w (2), b (512), K or KiB (1024),
+// kB or KB (1000), and so on with M, G and T. It returns a pointer to the
+// value, initialized to value.
+func (k *Keys) Size(name string, value int64, help string) *int64 {
+	p := new(int64)
+	*p = value
+	k.Var(name, (*sizeValue)(p), help)
+	return p
+}
+
+// Parse parses the key=value pairs in args. The values are checked by the
+// validators of their key, then stored. A rejected value is reported as a
+// *ValueError naming the key. The words which are not pairs are returned,
+// in order, with the unknown pairs if the handling is UnknownPass.
+// Parse stops at the first error.
+func (k *Keys) Parse(args []string) ([]string, error) {
+	var operands []string
+	for _, arg := range args {
+		i := strings.IndexByte(arg, '=')
+		if i <= 0 {
+			operands = append(operands, arg)
+			continue
+		}
+		name, value := arg[:i], arg[i+1:]
+		d, found := k.keys[name]
+		if !found {
+			switch k.unknown {
+			case UnknownCollect:
+				k.extra = append(k.extra, arg)
+			case UnknownPass:
+				operands = append(operands, arg)
+			default:
+				return operands, &ValueError{name, value, ErrKey}
+			}
+			continue
+		}
+		for _, v := range d.validators {
+			if e := v(value); e != nil {
+				return operands, &ValueError{name, value, e}
+			}
+		}
+		if e := d.value.Set(value); e != nil {
+			return operands, &ValueError{name, value, e}
+		}
+	}
+	return operands, nil
+}
+
+// Unknown returns the unknown key=value pairs collected by Parse, if the
+// handling is UnknownCollect.
+func (k *Keys) Unknown() []string {
+	return k.extra
+}
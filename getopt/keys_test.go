@@ -0,0 +1,62 @@
+package getopt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	args := []string{"test", "-v", "if=in.img", "bs=4M", "count=0x10", "out", "x=1"}
+	p := NewParser(args, "v")
+	k := p.Keys(UnknownCollect)
+	in := k.String("if", "-", "read from `file`")
+	out := k.String("of", "-", "write to `file`")
+	bs := k.Size("bs", 512, "block size")
+	count := k.Int("count", -1, "copy `n` blocks")
+	for o, _ := p.Option(); o != EndOption; o, _ = p.Option() {
+	}
+	operands, e := k.Parse(p.Args())
+	if e != nil {
+		t.Fatal(e)
+	}
+	if *in != "in.img" || *out != "-" || *bs != 4<<20 || *count != 16 {
+		t.Errorf("values: %q, %q, %d, %d", *in, *out, *bs, *count)
+	}
+	if strings.Join(operands, ",") != "out" {
+		t.Errorf("operands: %q", operands)
+	}
+	if strings.Join(k.Unknown(), ",") != "x=1" {
+		t.Errorf("unknown pairs: %q", k.Unknown())
+	}
+}
+
+func TestKeysErrors(t *testing.T) {
+	k := NewKeys(UnknownPass)
+	k.Size("bs", 512, "block size")
+	k.String("conv", "", "conversions")
+	k.SetValidator("conv", Choice("ucase", "lcase"))
+	operands, e := k.Parse([]string{"x=1", "bs=1kB"})
+	if e != nil || strings.Join(operands, ",") != "x=1" {
+		t.Errorf("Parse() returned %q, %v", operands, e)
+	}
+	tests := []struct {
+		arg string
+		err error
+	}{
+		{"bs=4X", nil},
+		{"bs=99999999999T", nil},
+		{"conv=swab", ErrChoice},
+	}
+	for _, test := range tests {
+		_, e := k.Parse([]string{test.arg})
+		var v *ValueError
+		if !errors.As(e, &v) || (test.err != nil && !errors.Is(e, test.err)) {
+			t.Errorf("%s: Parse() returned %v", test.arg, e)
+		}
+	}
+	k = NewKeys(UnknownError)
+	if _, e := k.Parse([]string{"x=1"}); !errors.Is(e, ErrKey) {
+		t.Errorf("Parse() returned %v, not ErrKey", e)
+	}
+}
@@ -0,0 +1,125 @@
+package getopt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// A Prompter asks the user for the arguments of the required options that
+// were not given on the command line. See Parser.SetPrompter.
+type Prompter struct {
+	in   *bufio.Reader    // where the answers are read from
+	out  io.Writer        // where the questions are written to
+	echo func(bool) error // turns the echo of the terminal on or off
+}
+
+// IsTerminal reports whether f is a terminal (a character device).
+func IsTerminal(f *os.File) bool {
+	info, e := f.Stat()
+	return e == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// stty returns a function turning the echo of the terminal f on or off by
+// running the stty command.
+func stty(f *os.File) func(bool) error {
+	return func(on bool) error {
+		arg := "-echo"
+		if on {
+			arg = "echo"
+		}
+		cmd := exec.Command("stty", arg)
+		cmd.Stdin = f
+		return cmd.Run()
+	}
+}
+
+// NewPrompter returns a Prompter reading the answers from in and writing the
+// questions to out. If in is a terminal, the echo is turned off by stty(1)
+// while reading secret arguments.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	q := &Prompter{in: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok && IsTerminal(f) {
+		q.echo = stty(f)
+	}
+	return q
+}
+
+// SetEcho sets the function turning the echo of the input on or off while
+// a secret argument is read. If echo is nil, the echo is left unchanged.
+func (q *Prompter) SetEcho(echo func(on bool) error) {
+	q.echo = echo
+}
+
+// readLine reads an answer, without its end-of-line.
+func (q *Prompter) readLine(secret bool) (string, error) {
+	if secret && q.echo != nil {
+		if e := q.echo(false); e == nil {
+			defer fmt.Fprintln(q.out)
+			defer q.echo(true)
+		}
+	}
+	s, e := q.in.ReadString('\n')
+	if e != nil && (e != io.EOF || s == "") {
+		return "", e
+	}
+	return strings.TrimRight(s, "\r\n"), nil
+}
+
+// ask asks for the argument of the option o until a valid one is given. The
+// argument becomes the argument of the option, as if given on the command
+// line. At the end of the input, it returns ErrRequired.
+func (q *Prompter) ask(p *Parser, o rune) error {
+	c := p.catalog()
+	s := p.spec(o)
+	label := strings.ReplaceAll(s.help, "`", "")
+	if label == "" {
+		label = p.optName
+	}
+	for {
+		for i, choice := range s.choices {
+			fmt.Fprintln(q.out, c.Message("prompt.choice", strconv.Itoa(i+1), choice))
+		}
+		if s.hasValue && !s.secret {
+			fmt.Fprint(q.out, c.Message("prompt.default", label, s.value))
+		} else {
+			fmt.Fprint(q.out, c.Message("prompt.value", label))
+		}
+		value, e := q.readLine(s.secret)
+		if e != nil {
+			return ErrRequired
+		}
+		if value == "" {
+			if !s.hasValue {
+				continue
+			}
+			value = s.value
+		}
+		if n, e := strconv.Atoi(value); e == nil && n > 0 && n <= len(s.choices) {
+			if Choice(s.choices...)(value) != nil {
+				value = s.choices[n-1]
+			}
+		}
+		p.optArgs = []string{value}
+		if e := p.validate(o, p.optName); e != nil {
+			fmt.Fprintln(q.out, p.Message(e))
+			continue
+		}
+		return nil
+	}
+}
+
+// SetPrompter sets the Prompter asking for the missing required options. If
+// q is nil, Option returns ErrRequired for them. A program would usually
+// set a Prompter only if its standard input is a terminal:
+//
+//	if getopt.IsTerminal(os.Stdin) {
+//		p.SetPrompter(getopt.NewPrompter(os.Stdin, os.Stderr))
+//	}
+func (p *Parser) SetPrompter(q *Prompter) {
+	p.prompter = q
+}
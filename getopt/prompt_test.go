@@ -0,0 +1,79 @@
+package getopt
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	args := []string{"test", "-u", "root", "file"}
+	p := NewParser(args, "u:p:")
+	p.Require('u')
+	p.Require('p')
+	var seen []string
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		seen = append(seen, string(o)+p.OptArg())
+		if o == 'p' && e != ErrRequired {
+			t.Errorf("-p: Option() returned %v, not ErrRequired", e)
+		}
+	}
+	if strings.Join(seen, ",") != "uroot,p" {
+		t.Errorf("options returned: %q", seen)
+	}
+	if strings.Join(p.Args(), ",") != "file" {
+		t.Errorf("Args() returned %q", p.Args())
+	}
+}
+
+func TestPrompter(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	in := strings.NewReader("\nsecret\n4\n2\n\n")
+	var out strings.Builder
+	echo := ""
+	q := NewPrompter(in, &out)
+	q.SetEcho(func(on bool) error {
+		echo += strconv.FormatBool(on) + ","
+		return nil
+	})
+	p := NewParser([]string{"test"}, "p:c:s:")
+	p.SetPrompter(q)
+	p.Require('p')
+	p.SetHelp('p', "the `password`")
+	p.SetSecret('p')
+	p.Require('c')
+	p.SetChoices('c', "red", "green", "blue")
+	p.Require('s')
+	p.SetDefault('s', "medium")
+	p.SetHelp('s', "size")
+	var seen []string
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			t.Errorf("%s: %s", e, p.OptName())
+		}
+		seen = append(seen, string(o)+"="+p.OptArg())
+	}
+	if strings.Join(seen, ",") != "p=secret,c=green,s=medium" {
+		t.Errorf("options returned: %q", seen)
+	}
+	if echo != "false,true,false,true," {
+		t.Errorf("echo changes: %q", echo)
+	}
+	prompts := "the password: \n" +
+		"the password: \n" +
+		"1) red\n2) green\n3) blue\n-c: " +
+		"invalid value \"4\" for option -c: not among the choices\n" +
+		"1) red\n2) green\n3) blue\n-c: " +
+		"size [medium]: "
+	if out.String() != prompts {
+		t.Errorf("prompts: %q", out.String())
+	}
+}
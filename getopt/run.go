@@ -0,0 +1,119 @@
+package getopt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"syscall"
+)
+
+// The exit codes of sysexits.h used by Run.
+const (
+	ExitOK       = 0  // successful termination
+	ExitFailure  = 1  // any other error
+	ExitUsage    = 64 // command line usage error
+	ExitSoftware = 70 // internal software error, like a panic
+	ExitConfig   = 78 // configuration error
+)
+
+// ErrConfig may be wrapped by the errors about the configuration of the
+// program, so that Run maps them to ExitConfig.
+var ErrConfig = errors.New("getopt: configuration error")
+
+// An ExitCoder is an error telling the code the program should exit with.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// ExitCode returns the exit code for the error e: ExitOK for nil, the code
+// given by the first ExitCoder in the chain of e, ExitUsage for the errors
+// returned by Option, Keys.Parse and SplitArgs, for ErrCommand, ExitConfig
+// for ErrConfig and ExitFailure otherwise.
+func ExitCode(e error) int {
+	var c ExitCoder
+	var v *ValueError
+	switch {
+	case e == nil:
+		return ExitOK
+	case errors.As(e, &c):
+		return c.ExitCode()
+	case errors.As(e, &v), errors.Is(e, ErrOption), errors.Is(e, ErrNoArg),
+		errors.Is(e, ErrFewArgs), errors.Is(e, ErrArg),
+		errors.Is(e, ErrRequired), errors.Is(e, ErrSplit),
+		errors.Is(e, ErrCommand):
+		return ExitUsage
+	case errors.Is(e, ErrConfig):
+		return ExitConfig
+	}
+	return ExitFailure
+}
+
+// A Runner runs the main function of a program. The zero value takes the
+// name of the program from os.Args, writes to os.Stderr and cancels the
+// context on SIGINT and SIGTERM.
+type Runner struct {
+	Program string      // the name of the program, shown before errors
+	Parser  *Parser     // if not nil, used to show localized messages
+	Stderr  io.Writer   // where the errors go
+	Stack   bool        // whether the stack is dumped after a panic
+	Signals []os.Signal // the signals canceling the context
+}
+
+// Run calls f with a context canceled when one of the signals arrives, and
+// returns the exit code for the error returned by f, see ExitCode. A non-nil
+// error is printed, preceded by the name of the program. A panic in f is
+// recovered and printed, with the stack if r.Stack is true, and gives the
+// code ExitSoftware. Run doesn't call os.Exit, it's left to the caller:
+//
+//	func main() {
+//		os.Exit(getopt.Run(run))
+//	}
+func (r *Runner) Run(f func(ctx context.Context) error) (code int) {
+	stderr := r.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	program := r.Program
+	if program == "" && r.Parser != nil {
+		program = r.Parser.program()
+	}
+	if program == "" {
+		program = filepath.Base(os.Args[0])
+	}
+	signals := r.Signals
+	if signals == nil {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+	defer func() {
+		if x := recover(); x != nil {
+			fmt.Fprintf(stderr, "%s: panic: %v\n", program, x)
+			if r.Stack {
+				stderr.Write(debug.Stack())
+			}
+			code = ExitSoftware
+		}
+	}()
+	e := f(ctx)
+	if e != nil {
+		message := e.Error()
+		if r.Parser != nil {
+			message = r.Parser.Message(e)
+		}
+		fmt.Fprintf(stderr, "%s: %s\n", program, message)
+	}
+	return ExitCode(e)
+}
+
+// Run runs f with a zero Runner.
+func Run(f func(ctx context.Context) error) int {
+	var r Runner
+	return r.Run(f)
+}
@@ -0,0 +1,85 @@
+package getopt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit %d", int(e))
+}
+
+func (e exitError) ExitCode() int {
+	return int(e)
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOK},
+		{errors.New("failed"), ExitFailure},
+		{ErrOption, ExitUsage},
+		{&ValueError{"-n", "x", ErrRange}, ExitUsage},
+		{fmt.Errorf("reading: %w", ErrConfig), ExitConfig},
+		{fmt.Errorf("wrapped: %w", exitError(3)), 3},
+	}
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Errorf("%v: ExitCode() returned %d, not %d", test.err, code, test.code)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	var stderr strings.Builder
+	p := NewParser([]string{"/bin/tool", "-x"}, "")
+	r := Runner{Parser: p, Stderr: &stderr}
+	code := r.Run(func(ctx context.Context) error {
+		_, e := p.Option()
+		return e
+	})
+	if code != ExitUsage || stderr.String() != "tool: option -x not supported\n" {
+		t.Errorf("Run() returned %d, errors:\n%s", code, stderr.String())
+	}
+	stderr.Reset()
+	r = Runner{Program: "tool", Stderr: &stderr, Stack: true}
+	code = r.Run(func(ctx context.Context) error {
+		panic("oops")
+	})
+	if code != ExitSoftware || !strings.HasPrefix(stderr.String(), "tool: panic: oops\ngoroutine") {
+		t.Errorf("Run() returned %d, errors:\n%s", code, stderr.String())
+	}
+}
+
+func TestRunSignal(t *testing.T) {
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Skip(err)
+	}
+	var stderr strings.Builder
+	r := Runner{Program: "tool", Stderr: &stderr}
+	code := r.Run(func(ctx context.Context) error {
+		if e := self.Signal(os.Interrupt); e != nil {
+			t.Skip(e)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+	if code != ExitFailure || stderr.String() != "tool: context canceled\n" {
+		t.Errorf("Run() returned %d, errors:\n%s", code, stderr.String())
+	}
+}
@@ -0,0 +1,169 @@
+package getopt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrSplit is returned when a string can't be split into arguments.
+var ErrSplit = errors.New("getopt: invalid argument string")
+
+// SplitShebang splits the interpreter arguments of a script started through
+// a "#!" line, before giving the arguments to NewParser. On Linux, a script
+// beginning with "#!/usr/bin/tool -x -y" and run as "script a b" starts the
+// tool with the arguments "/usr/bin/tool", "-x -y", "script", "a" and "b":
+// all the flags of the "#!" line are in a single word.
+// The policy is to split args[1] only when all of these conditions hold:
+//   - args holds at least three items;
+//   - args[1] starts with '-' and contains a space or a tab;
+//   - args[2] names a file whose first line starts with "#!" and ends with
+//     args[1], once stripped like the kernel does of its trailing spaces
+//     and tabs. A '\r' before the end-of-line is kept, as in args[1].
+//
+// Then args[1] is split by SplitArgs and the result replaces it. Otherwise,
+// args is returned as is. The file is opened with os.Open; getenv is used
+// as by SplitArgs.
+func SplitShebang(args []string, getenv func(string) string) ([]string, error) {
+	if len(args) < 3 || !strings.HasPrefix(args[1], "-") ||
+		!strings.ContainsAny(args[1], " \t") {
+		return args, nil
+	}
+	f, e := os.Open(args[2])
+	if e != nil {
+		return args, nil
+	}
+	line, _ := bufio.NewReaderSize(f, 256).ReadString('\n')
+	f.Close()
+	line = strings.TrimRight(strings.TrimSuffix(line, "\n"), " \t")
+	if !strings.HasPrefix(line, "#!") || !strings.HasSuffix(line, args[1]) {
+		return args, nil
+	}
+	words, e := SplitArgs(args[1], getenv)
+	if e != nil {
+		return args, e
+	}
+	split := append([]string{args[0]}, words...)
+	return append(split, args[2:]...), nil
+}
+
+// SplitArgs splits s into arguments, following the rules of "env -S":
+//   - the arguments are separated by spaces, tabs, end-of-lines and
+//     form feeds;
+//   - the characters between single quotes are taken literally, except
+//     for the escape sequences \\ and \';
+//   - between double quotes, the separators are taken literally, but the
+//     escape sequences and the variables are still replaced;
+//   - the escape sequences are \t, \n, \v, \f, \r, \\, \', \", \#, \$ and
+//     \_, which is a space between double quotes and a separator elsewhere;
+//     \c ignores the rest of s;
+//   - ${NAME} is replaced by the value given by getenv, or by the empty
+//     string if getenv is nil;
+//   - a '#' starting an argument starts a comment, up to the end of s.
+//
+// Unterminated quotes, unknown escapes and variables not enclosed in braces
+// are reported as errors wrapping ErrSplit.
+func SplitArgs(s string, getenv func(string) string) ([]string, error) {
+	var args []string
+	var b strings.Builder
+	word := false // whether a word was started
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == '\'') {
+				i++
+				b.WriteByte(s[i])
+			} else {
+				b.WriteByte(c)
+			}
+			continue
+		case c == '\'' || c == '"':
+			if quote == c {
+				quote = 0
+			} else if quote == 0 {
+				quote = c
+			} else {
+				b.WriteByte(c)
+			}
+			word = true
+			continue
+		case quote == 0 && strings.IndexByte(" \t\n\v\f\r", c) >= 0:
+			if word {
+				args = append(args, b.String())
+				b.Reset()
+				word = false
+			}
+			continue
+		case quote == 0 && c == '#' && !word:
+			return args, nil
+		case c == '$':
+			if i+1 >= len(s) || s[i+1] != '{' {
+				return nil, fmt.Errorf("%w: variable without braces", ErrSplit)
+			}
+			j := strings.IndexByte(s[i:], '}')
+			if j < 0 {
+				return nil, fmt.Errorf("%w: unterminated variable", ErrSplit)
+			}
+			if getenv != nil {
+				b.WriteString(getenv(s[i+2 : i+j]))
+			}
+			i += j
+			word = true
+			continue
+		case c != '\\':
+			b.WriteByte(c)
+			word = true
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return nil, fmt.Errorf("%w: backslash at the end", ErrSplit)
+		}
+		switch c = s[i]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\', '\'', '"', '#', '$':
+			b.WriteByte(c)
+		case '_':
+			if quote == 0 {
+				if word {
+					args = append(args, b.String())
+					b.Reset()
+					word = false
+				}
+				continue
+			}
+			b.WriteByte(' ')
+		case 'c':
+			if quote != 0 {
+				return nil, fmt.Errorf("%w: \\c inside quotes", ErrSplit)
+			}
+			i = len(s)
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unknown escape \\%c", ErrSplit, c)
+		}
+		word = true
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrSplit)
+	}
+	if word {
+		args = append(args, b.String())
+	}
+	return args, nil
+}
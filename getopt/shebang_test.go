@@ -0,0 +1,78 @@
+package getopt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "EMPTY": ""}
+	getenv := func(name string) string { return env[name] }
+	tests := []struct {
+		s    string
+		args string
+	}{
+		{"-x -y", "-x|-y"},
+		{"  -a\t 'b c'  \"d e\" ", "-a|b c|d e"},
+		{`-f '${HOME}' "${HOME}/x" ${HOME}`, `-f|${HOME}|/home/me/x|/home/me`},
+		{`a\_b "a\_b" a\tb`, "a|b|a b|a\tb"},
+		{`'it\'s' "say \"hi\"" \$x \#y`, `it's|say "hi"|$x|#y`},
+		{`-x #comment -y`, "-x"},
+		{`-x a#b \c -y`, "-x|a#b"},
+		{`"" ${EMPTY} ''`, "||"},
+	}
+	for _, test := range tests {
+		args, e := SplitArgs(test.s, getenv)
+		if e != nil {
+			t.Errorf("%q: %v", test.s, e)
+		}
+		if strings.Join(args, "|") != test.args {
+			t.Errorf("%q: SplitArgs() returned %q", test.s, args)
+		}
+	}
+	for _, s := range []string{`'a`, `"a`, `$HOME`, `${HOME`, `a\`, `\q`, `"\c"`} {
+		if _, e := SplitArgs(s, getenv); !errors.Is(e, ErrSplit) {
+			t.Errorf("%q: SplitArgs() returned %v, not ErrSplit", s, e)
+		}
+	}
+}
+
+func TestSplitShebang(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "script")
+	err := os.WriteFile(script, []byte("#!/usr/bin/tool -x -y 'a b'\nbody\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blank := filepath.Join(t.TempDir(), "blank")
+	err = os.WriteFile(blank, []byte("#!/usr/bin/tool -x -y 'a b' \t\nbody\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crlf := filepath.Join(t.TempDir(), "crlf")
+	err = os.WriteFile(crlf, []byte("#!/usr/bin/tool -x -y 'a b'\r\nbody\r\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args  []string
+		split string
+	}{
+		{[]string{"tool", "-x -y 'a b'", script, "c"}, "tool|-x|-y|a b|" + script + "|c"},
+		{[]string{"tool", "-x -y", script, "c"}, "tool|-x -y|" + script + "|c"},
+		{[]string{"tool", "-x -y 'a b'", blank}, "tool|-x|-y|a b|" + blank},
+		{[]string{"tool", "-x -y 'a b'\r", crlf}, "tool|-x|-y|a b|" + crlf},
+		{[]string{"tool", "-x -y 'a b'", crlf}, "tool|-x -y 'a b'|" + crlf},
+		{[]string{"tool", "-x -y 'a b'", "missing", "c"}, "tool|-x -y 'a b'|missing|c"},
+		{[]string{"tool", "-x", script}, "tool|-x|" + script},
+		{[]string{"tool", "-x -y 'a b'"}, "tool|-x -y 'a b'"},
+	}
+	for _, test := range tests {
+		args, e := SplitShebang(test.args, nil)
+		if e != nil || strings.Join(args, "|") != test.split {
+			t.Errorf("%q: SplitShebang() returned %q, %v", test.args, args, e)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package getopt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrCommand is returned by Shell.Exec for a command which wasn't added.
+var ErrCommand = errors.New("getopt: command not found")
+
+// A CommandError is returned by Shell.Exec when a command fails.
+type CommandError struct {
+	Command string // the name of the command
+	Message string // the text describing Err, from the catalog
+	Err     error  // the error returned by the command or its Parser
+}
+
+// Error returns the name of the command followed by the message.
+func (e *CommandError) Error() string {
+	return e.Command + ": " + e.Message
+}
+
+// Unwrap returns the error returned by the command or its Parser.
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// A Command is a command run by a Shell. Its arguments are parsed by a
+// Parser created with the options string Opts, then given to Setup for the
+// other declarations.
+type Command struct {
+	Name     string                        // the first word of the line
+	Opts     string                        // the options string
+	Help     string                        // the description shown by help
+	Setup    func(p *Parser)               // declares the options, may be nil
+	Run      func(p *Parser) error         // runs the command
+	Complete func(words []string) []string // completes the last word, may be nil
+}
+
+// A Shell reads command lines and runs the commands they name. Each line
+// is split like by SplitArgs, the first word naming the command and the
+// others being parsed by the Parser of the command. A line ending with a
+// backslash continues on the next line. Besides the commands added by Add,
+// a shell knows "help", showing the commands or the help of one of them,
+// "history", showing the lines read, and "exit". The zero value reads from
+// os.Stdin, writes to os.Stdout and os.Stderr and keeps no history file.
+type Shell struct {
+	Prompt      string              // written before each line, if not empty
+	Stdin       io.Reader           // where the lines are read
+	Stdout      io.Writer           // where the prompt and the help go
+	Stderr      io.Writer           // where the errors go
+	HistoryFile string              // the file keeping the lines, if not empty
+	Getenv      func(string) string // gives the values of ${NAME}
+	Catalog     *Catalog            // the messages, DefaultCatalog if nil
+	commands    map[string]*Command
+	history     []string
+}
+
+// builtins are the commands known by any shell.
+var builtins = []string{"exit", "help", "history"}
+
+// Add adds the command c to the shell, replacing any command, including a
+// built-in one, having the same name.
+func (s *Shell) Add(c Command) {
+	if s.commands == nil {
+		s.commands = make(map[string]*Command)
+	}
+	s.commands[c.Name] = &c
+}
+
+// History returns the lines read by the shell, preceded by those found in
+// the history file when Run started.
+func (s *Shell) History() []string {
+	return s.history
+}
+
+// catalog returns the catalog of the messages shown by the shell.
+func (s *Shell) catalog() *Catalog {
+	if s.Catalog == nil {
+		s.Catalog = DefaultCatalog()
+	}
+	return s.Catalog
+}
+
+// parser returns the parser of the command c for the given args, the first
+// one being the name of the command.
+func (s *Shell) parser(c *Command, args []string) *Parser {
+	p := NewParser(args, c.Opts)
+	p.SetCatalog(s.catalog())
+	if c.Setup != nil {
+		c.Setup(p)
+	}
+	return p
+}
+
+// Exec runs the command named by args[0], with the other arguments. This
+// allows running the commands of the shell from the command line, like in
+// "tool CMD ARGS...". The errors are returned as *CommandError, with a
+// message in the language of the catalog.
+func (s *Shell) Exec(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	name := args[0]
+	c, found := s.commands[name]
+	switch {
+	case found:
+	case name == "help":
+		return s.help(args[1:])
+	case name == "history":
+		for i, line := range s.history {
+			fmt.Fprintf(s.stdout(), "%5d  %s\n", i+1, line)
+		}
+		return nil
+	case name == "exit":
+		return nil
+	default:
+		return &CommandError{name, s.catalog().Message("shell.unknown"),
+			ErrCommand}
+	}
+	p := s.parser(c, args)
+	if e := c.Run(p); e != nil {
+		return &CommandError{name, p.Message(e), e}
+	}
+	return nil
+}
+
+// help writes the list of the commands or, if args names a command, its
+// help text.
+func (s *Shell) help(args []string) error {
+	if len(args) > 0 {
+		c, found := s.commands[args[0]]
+		if !found {
+			return &CommandError{args[0], s.catalog().Message("shell.unknown"),
+				ErrCommand}
+		}
+		io.WriteString(s.stdout(), s.parser(c, args[:1]).Usage())
+		return nil
+	}
+	var lines []usageLine
+	for _, name := range s.names() {
+		var help string
+		if c, found := s.commands[name]; found {
+			help = c.Help
+		} else {
+			help = s.catalog().Message("shell." + name)
+		}
+		lines = append(lines, usageLine{name, help})
+	}
+	var b strings.Builder
+	b.WriteString(s.catalog().Message("shell.commands"))
+	b.WriteByte('\n')
+	writeLines(&b, lines)
+	io.WriteString(s.stdout(), b.String())
+	return nil
+}
+
+// names returns the sorted names of the commands, including the built-in
+// ones.
+func (s *Shell) names() []string {
+	names := append([]string(nil), builtins...)
+	for name := range s.commands {
+		if !isBuiltin(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isBuiltin reports whether name is the name of a built-in command.
+func isBuiltin(name string) bool {
+	for _, b := range builtins {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete returns the sorted words that may replace the last word of
+// line, which is the empty string if line ends with a space. The first
+// word is completed with the names of the commands. The others are
+// completed by the Complete function of the command or, if it's nil, by
+// the choices of the option taking the word as argument (see SetChoices)
+// or, if the word starts like a long option, by its long options which are
+// not hidden or deprecated.
+func (s *Shell) Complete(line string) []string {
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.TrimRight(line, " \t") != line {
+		words = append(words, "")
+	}
+	last := words[len(words)-1]
+	var candidates []string
+	switch c, found := s.commands[words[0]]; {
+	case len(words) == 1, words[0] == "help" && !found:
+		candidates = s.names()
+	case !found:
+	case c.Complete != nil:
+		return c.Complete(words)
+	default:
+		p := s.parser(c, words[:1])
+		candidates = p.complete(words[len(words)-2], last)
+		sort.Strings(candidates)
+	}
+	var matches []string
+	for _, w := range candidates {
+		if strings.HasPrefix(w, last) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+// complete returns the words which may be typed after the word prev in
+// place of the word last: the choices of the option taking last as
+// argument or, if last starts like a long option, the long options which
+// are not hidden or deprecated.
+func (p *Parser) complete(prev, last string) []string {
+	var words []string
+	if p.long != "" && strings.HasPrefix(last, p.long) {
+		if name, _, found := strings.Cut(last[len(p.long):], "="); found {
+			if l := p.findLong(name); l != nil {
+				for _, c := range p.choices(l.o) {
+					words = append(words, p.long+name+"="+c)
+				}
+			}
+			return words
+		}
+	}
+	if o := p.argOption(prev); o != EndOption {
+		return p.choices(o)
+	}
+	if p.long == "" || !strings.HasPrefix(last, p.long[:1]) {
+		return nil
+	}
+	for _, l := range p.longs {
+		if s, found := p.specs[l.o]; l.o < 0 || l.deprecated || found && s.hidden {
+			continue
+		}
+		words = append(words, p.long+l.name)
+	}
+	return words
+}
+
+// argOption returns the option whose argument is the word following w, or
+// EndOption if w doesn't end with an option taking an argument.
+func (p *Parser) argOption(w string) rune {
+	if p.long != "" && strings.HasPrefix(w, p.long) {
+		if l := p.findLong(w[len(p.long):]); l != nil && p.takesArgs(l.o) {
+			return l.o
+		}
+		if !p.fallback(w) {
+			return EndOption
+		}
+	}
+	if len(w) < 2 || strings.IndexByte(p.shorts, w[0]) < 0 {
+		return EndOption
+	}
+	for i := 1; i < len(w); i++ {
+		o := rune(w[i])
+		if s, found := p.specs[o]; found && s.alias != 0 {
+			o = s.alias
+		}
+		if p.takesArgs(o) {
+			if i == len(w)-1 {
+				return o
+			}
+			break
+		}
+	}
+	return EndOption
+}
+
+// choices returns the choices of the arguments of the option o.
+func (p *Parser) choices(o rune) []string {
+	if s, found := p.specs[o]; found {
+		return append([]string(nil), s.choices...)
+	}
+	return nil
+}
+
+// Run reads the lines and runs their commands, until "exit" or the end of
+// the input. The errors of the commands are written to s.Stderr and don't
+// stop the shell. Each line which isn't empty is added to the history and
+// appended to the history file. Run returns the errors of reading the input
+// or of opening the history file.
+func (s *Shell) Run() error {
+	in := s.Stdin
+	if in == nil {
+		in = os.Stdin
+	}
+	stderr := s.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	var history io.Writer = io.Discard
+	if s.HistoryFile != "" {
+		data, e := os.ReadFile(s.HistoryFile)
+		if e != nil && !errors.Is(e, os.ErrNotExist) {
+			return e
+		}
+		if text := strings.TrimSuffix(string(data), "\n"); text != "" {
+			s.history = append(s.history, strings.Split(text, "\n")...)
+		}
+		f, e := os.OpenFile(s.HistoryFile,
+			os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if e != nil {
+			return e
+		}
+		defer f.Close()
+		history = f
+	}
+	r := bufio.NewReader(in)
+	for {
+		line, e := s.readLine(r)
+		if e != nil {
+			if e == io.EOF {
+				return nil
+			}
+			return e
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s.history = append(s.history, line)
+		fmt.Fprintln(history, line)
+		args, e := SplitArgs(line, s.Getenv)
+		if e != nil {
+			fmt.Fprintln(stderr, s.catalog().Message("shell.split",
+				strings.TrimPrefix(e.Error(), ErrSplit.Error()+": ")))
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if _, found := s.commands[args[0]]; !found && args[0] == "exit" {
+			return nil
+		}
+		if e := s.Exec(args); e != nil {
+			fmt.Fprintln(stderr, e)
+		}
+	}
+}
+
+// readLine writes the prompt and reads a line, joining the lines ending
+// with an odd number of backslashes to the next one. It returns io.EOF only
+// if there was nothing left to read.
+func (s *Shell) readLine(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	prompt := s.Prompt
+	for {
+		if prompt != "" {
+			io.WriteString(s.stdout(), prompt)
+		}
+		line, e := r.ReadString('\n')
+		if e != nil && (e != io.EOF || line == "" && b.Len() == 0) {
+			return "", e
+		}
+		line = strings.TrimRight(line, "\r\n")
+		n := len(line) - len(strings.TrimRight(line, "\\"))
+		if e != nil || n%2 == 0 {
+			b.WriteString(line)
+			return b.String(), nil
+		}
+		b.WriteString(line[:len(line)-1])
+		if prompt != "" {
+			prompt = "> "
+		}
+	}
+}
+
+// stdout returns where the prompt and the help go.
+func (s *Shell) stdout() io.Writer {
+	if s.Stdout == nil {
+		return os.Stdout
+	}
+	return s.Stdout
+}
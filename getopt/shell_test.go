@@ -0,0 +1,180 @@
+package getopt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newShell(input string) (*Shell, *strings.Builder, *strings.Builder) {
+	var stdout, stderr strings.Builder
+	s := &Shell{Stdin: strings.NewReader(input), Stdout: &stdout, Stderr: &stderr}
+	s.Add(Command{
+		Name: "greet",
+		Opts: "n:u",
+		Help: "greet someone",
+		Setup: func(p *Parser) {
+			p.Long("name", 'n')
+			p.Long("upper", 'u')
+			p.SetHelp('n', "the `name` to greet")
+		},
+		Run: func(p *Parser) error {
+			name, upper := "world", false
+			for o, e := p.Option(); o != EndOption; o, e = p.Option() {
+				switch {
+				case e != nil:
+					return e
+				case o == 'n':
+					name = p.OptArg()
+				case o == 'u':
+					upper = true
+				}
+			}
+			text := "hello " + strings.Join(append([]string{name}, p.Args()...), " ")
+			if upper {
+				text = strings.ToUpper(text)
+			}
+			stdout.WriteString(text + "\n")
+			return nil
+		},
+	})
+	return s, &stdout, &stderr
+}
+
+func TestShell(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	history := filepath.Join(t.TempDir(), "history")
+	os.WriteFile(history, []byte("greet\n"), 0600)
+	input := "greet -n 'big world'\n\n  # a comment\ngreet --upper \\\n  -n you\\\\\n" +
+		"frob\ngreet 'x\ngreet ${HOME\ngreet -x\nhelp\nhelp greet\nexit\ngreet\n"
+	s, stdout, stderr := newShell(input)
+	s.HistoryFile = history
+	if e := s.Run(); e != nil {
+		t.Fatal(e)
+	}
+	want := `hello big world
+HELLO YOU\
+Commands:
+  exit     leave the shell
+  greet    greet someone
+  help     show the commands, or the help of a command
+  history  show the lines read
+Usage: greet [options]
+
+Options:
+  -n, --name name  the name to greet
+  -u, --upper
+`
+	if stdout.String() != want {
+		t.Errorf("the output is\n%s\nwant\n%s", stdout.String(), want)
+	}
+	want = "frob: command not found\n" +
+		"invalid command line: unterminated quote\n" +
+		"invalid command line: unterminated variable\n" +
+		"greet: option -x not supported\n"
+	if stderr.String() != want {
+		t.Errorf("the errors are\n%s\nwant\n%s", stderr.String(), want)
+	}
+	lines := []string{"greet", "greet -n 'big world'", "  # a comment",
+		"greet --upper   -n you\\\\", "frob", "greet 'x", "greet ${HOME",
+		"greet -x", "help", "help greet", "exit"}
+	if !reflect.DeepEqual(s.History(), lines) {
+		t.Errorf("the history is %q, want %q", s.History(), lines)
+	}
+	data, _ := os.ReadFile(history)
+	if string(data) != strings.Join(lines, "\n")+"\n" {
+		t.Errorf("the history file holds %q", data)
+	}
+}
+
+func TestShellSplit(t *testing.T) {
+	c, e := LoadCatalog(messages, "messages/getopt", "fr_FR")
+	if e != nil {
+		t.Fatal(e)
+	}
+	s, _, stderr := newShell("greet 'x\n")
+	s.Catalog = c
+	if e := s.Run(); e != nil {
+		t.Fatal(e)
+	}
+	if want := "ligne de commande invalide : unterminated quote\n"; stderr.String() != want {
+		t.Errorf("the errors are %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestShellExec(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	s, stdout, _ := newShell("")
+	if e := s.Exec([]string{"greet", "-u", "all"}); e != nil {
+		t.Fatal(e)
+	}
+	if stdout.String() != "HELLO WORLD ALL\n" {
+		t.Errorf("the output is %q", stdout.String())
+	}
+	e := s.Exec([]string{"greet", "-n"})
+	var c *CommandError
+	if !errors.As(e, &c) || !errors.Is(e, ErrNoArg) || ExitCode(e) != ExitUsage {
+		t.Errorf("got the error %v", e)
+	}
+	if e.Error() != "greet: option -n requires an argument" {
+		t.Errorf("the message is %q", e.Error())
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	s, _, _ := newShell("")
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", []string{"exit", "greet", "help", "history"}},
+		{"h", []string{"help", "history"}},
+		{"help g", []string{"greet"}},
+		{"greet --", []string{"--name", "--upper"}},
+		{"greet -n x --u", []string{"--upper"}},
+		{"greet ", nil},
+		{"frob --", nil},
+	}
+	for _, test := range tests {
+		if got := s.Complete(test.line); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Complete(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+func TestShellCompleteOptions(t *testing.T) {
+	var s Shell
+	s.Add(Command{
+		Name: "c",
+		Opts: "m:vs",
+		Setup: func(p *Parser) {
+			p.Long("mode", 'm')
+			p.Long("verbose", 'v')
+			p.Long("secret-debug", 's')
+			p.Hide('s')
+			p.SetChoices('m', "slow", "fast", "full")
+		},
+		Run: func(p *Parser) error { return nil },
+	})
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"c --mode f", []string{"fast", "full"}},
+		{"c --mode=f", []string{"--mode=fast", "--mode=full"}},
+		{"c -vm ", []string{"fast", "full", "slow"}},
+		{"c -m s", []string{"slow"}},
+		{"c -mf ", nil},
+		{"c -v f", nil},
+		{"c --", []string{"--mode", "--verbose"}},
+		{"c --s", nil},
+	}
+	for _, test := range tests {
+		if got := s.Complete(test.line); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Complete(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
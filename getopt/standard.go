@@ -0,0 +1,134 @@
+package getopt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+// The options returned by Option for --help and --version, when they are
+// not handled by the Standard options.
+const (
+	helpOption    = -2
+	versionOption = -3
+)
+
+// Standard configures the standard options handled by a Parser, see
+// SetStandard. The zero value uses the name of the program from the
+// arguments, os.Stdout, os.Stderr, os.Exit and debug.ReadBuildInfo.
+type Standard struct {
+	Program   string                          // the name of the program
+	Stdout    io.Writer                       // where help and version go
+	Stderr    io.Writer                       // where the errors go
+	Exit      func(code int)                  // ends the program
+	BuildInfo func() (*debug.BuildInfo, bool) // gives the version
+	DebugArgs bool                            // adds --debug-args
+	short     bool                            // whether -h asks for help
+	debug     bool                            // whether --debug-args was seen
+}
+
+// SetStandard makes the parser handle the standard options: "--help" and,
+// unless the options string already holds 'h' or the short options are
+// disabled by SetPrefixes, "-h" print the help text returned by Usage;
+// "--version" prints the name of the program, the version of its main
+// module and the revision it was built from, with a note if the working
+// tree was modified. Both then exit with the code 0.
+// For any error returned by Option, the message and a hint about the help
+// option are printed, then the program exits with the code 2.
+// If s.DebugArgs is true, the parsing is traced and the hidden option
+// "--debug-args" makes Option write the trace as a table to s.Stderr when
+// the options end or an error is found.
+// If s.Exit returns, Option returns EndOption after help and version, and
+// the error otherwise.
+// The long options must not be disabled by SetPrefixes.
+func (p *Parser) SetStandard(s Standard) {
+	if s.Stdout == nil {
+		s.Stdout = os.Stdout
+	}
+	if s.Stderr == nil {
+		s.Stderr = os.Stderr
+	}
+	if s.Exit == nil {
+		s.Exit = os.Exit
+	}
+	if s.BuildInfo == nil {
+		s.BuildInfo = debug.ReadBuildInfo
+	}
+	if p.std == nil && p.shorts != "" && strings.IndexByte(p.opts, 'h') < 0 {
+		p.opts += "h"
+		s.short = true
+	} else if p.std != nil {
+		s.short = p.std.short
+	}
+	p.std = &s
+	p.Long("help", helpOption)
+	p.Long("version", versionOption)
+	if s.DebugArgs {
+		p.Long("debug-args", debugOption)
+		if p.trace == nil {
+			p.SetTrace(true)
+		}
+	}
+}
+
+// Version returns the text printed for the "--version" option.
+func (p *Parser) Version() string {
+	c := p.catalog()
+	version, revision, modified := "", "", false
+	get := debug.ReadBuildInfo
+	if p.std != nil {
+		get = p.std.BuildInfo
+	}
+	if info, ok := get(); ok {
+		version = info.Main.Version
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+	}
+	s := strings.TrimSpace(p.program() + " " + version)
+	if revision != "" {
+		if modified {
+			s += "\n" + c.Message("version.modified", revision)
+		} else {
+			s += "\n" + c.Message("version.revision", revision)
+		}
+	}
+	return s + "\n"
+}
+
+// standard handles the result of option for the standard options.
+func (p *Parser) standard(o rune, e error) (rune, error) {
+	s := p.std
+	if o == debugOption && e == nil && s.DebugArgs {
+		s.debug = true
+		return p.Option()
+	}
+	if s.debug && p.trace != nil && (o == EndOption || e != nil) {
+		s.debug = false
+		p.trace.WriteTable(s.Stderr)
+	}
+	switch {
+	case e != nil:
+		c := p.catalog()
+		fmt.Fprintf(s.Stderr, "%s: %s\n%s\n", p.program(), p.Message(e),
+			c.Message("usage.hint", p.program(), p.long+"help"))
+		s.Exit(2)
+		return o, e
+	case o == helpOption || (o == 'h' && s.short):
+		io.WriteString(s.Stdout, p.Usage())
+	case o == versionOption:
+		io.WriteString(s.Stdout, p.Version())
+	default:
+		return o, e
+	}
+	s.Exit(0)
+	p.done, p.checked, p.missing = true, true, nil
+	return EndOption, nil
+}
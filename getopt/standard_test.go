@@ -0,0 +1,117 @@
+package getopt
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func newStandard(args []string) (*Parser, *strings.Builder, *strings.Builder, *int) {
+	var stdout, stderr strings.Builder
+	code := -1
+	p := NewParser(args, "vo:c:n:q")
+	p.SetStandard(Standard{
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Exit:   func(c int) { code = c },
+		BuildInfo: func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{
+				Main: debug.Module{Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "0123abcd"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			}, true
+		},
+	})
+	return p, &stdout, &stderr, &code
+}
+
+func TestUsage(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	p, stdout, _, code := newStandard([]string{"/usr/bin/tool", "-v", "--help", "-x"})
+	p.SetSynopsis("FILE...")
+	p.Long("verbose", 'v')
+	p.Alias('V', 'v')
+	p.Alias('d', 'v')
+	p.Deprecate('d')
+	p.SetHelp('v', "print more messages")
+	p.Long("output", 'o')
+	p.SetHelp('o', "write to `file`")
+	p.SetDefault('o', "-")
+	p.Require('o')
+	p.SetChoices('c', "red", "green")
+	p.SetHelp('c', "use a color")
+	p.SetArgs('n', 1, 2)
+	p.SetIndirect('n', 0)
+	p.Hide('q')
+	p.Long("dry-run", 'D')
+	p.SetHelp('D', "do nothing")
+	k := p.Keys(UnknownError)
+	k.Size("bs", 512, "`size` of the blocks")
+	var seen []rune
+	for o, _ := p.Option(); o != EndOption; o, _ = p.Option() {
+		seen = append(seen, o)
+	}
+	if string(seen) != "v" || *code != 0 {
+		t.Errorf("options returned %q, exit code %d", string(seen), *code)
+	}
+	usage := `Usage: tool [options] FILE...
+
+Options:
+  -v, -V, --verbose  print more messages
+  -o, --output file  write to file (default: -) (required)
+  -c {red,green}     use a color
+  -n ARG [ARG]       (or @FILE, @- for the standard input)
+      --dry-run      do nothing
+  -h, --help         show this help and exit
+      --version      show the version and exit
+
+Operands:
+  bs=size  size of the blocks (default: 512)
+`
+	if stdout.String() != usage {
+		t.Errorf("Usage() returned\n%s", stdout.String())
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	p, stdout, _, code := newStandard([]string{"tool", "--version"})
+	p.Option()
+	if stdout.String() != "tool v1.2.3\nrevision 0123abcd, modified\n" || *code != 0 {
+		t.Errorf("exit code %d, version:\n%s", *code, stdout.String())
+	}
+}
+
+func TestStandardError(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	p, stdout, stderr, code := newStandard([]string{"tool", "-x"})
+	if o, e := p.Option(); o != 'x' || e != ErrOption {
+		t.Errorf("Option() returned %c, %v", o, e)
+	}
+	if stdout.Len() != 0 || *code != 2 || stderr.String() !=
+		"tool: option -x not supported\nTry 'tool --help' for more information.\n" {
+		t.Errorf("exit code %d, errors:\n%s", *code, stderr.String())
+	}
+}
+
+func TestStandardNoShorts(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	p, stdout, _, code := newStandard([]string{"tool", "--help"})
+	p.SetPrefixes("", "--")
+	p.Long("verbose", 'v')
+	if o, e := p.Option(); o != EndOption || e != nil || *code != 0 {
+		t.Errorf("Option() returned %c, %v, exit code %d", o, e, *code)
+	}
+	usage := `Usage: tool [options]
+
+Options:
+      --verbose
+      --help     show this help and exit
+      --version  show the version and exit
+`
+	if stdout.String() != usage {
+		t.Errorf("Usage() returned\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,126 @@
+package getopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// debugOption is the option returned by Option for --debug-args, when it
+// is not handled by the Standard options.
+const debugOption = -4
+
+// A Kind tells how a part of an argument word was read by the Parser.
+type Kind int
+
+const (
+	KindOption     Kind = iota // an option, alone or in a cluster
+	KindAttached               // an argument in the word of its option
+	KindSeparate               // an argument in a word of its own
+	KindTerminator             // the "--" ending the options
+	KindOperand                // a word left after the options
+)
+
+var kindNames = [...]string{"option", "attached", "separate", "terminator",
+	"operand"}
+
+// String returns the name of the kind, like "attached".
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+// MarshalText returns the name of the kind, so that it shows as a string
+// in JSON.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// A Step records how the bytes from Start to End of the argument word at
+// Index were read. Option is the option as typed, for the options and
+// their arguments. Text holds the bytes read, or "***" for the arguments
+// of the options marked by SetSecret.
+type Step struct {
+	Index  int    `json:"index"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Kind   Kind   `json:"kind"`
+	Option string `json:"option,omitempty"`
+	Text   string `json:"text"`
+}
+
+// A Trace holds the steps recorded by a Parser, in the order of the
+// arguments.
+type Trace struct {
+	Steps []Step
+}
+
+// WriteTable writes the steps to w as a table with aligned columns, one
+// line per step.
+func (t *Trace) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tBYTES\tKIND\tOPTION\tTEXT")
+	for _, s := range t.Steps {
+		fmt.Fprintf(tw, "%d\t%d-%d\t%s\t%s\t%q\n", s.Index, s.Start, s.End,
+			s.Kind, s.Option, s.Text)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the steps to w as a JSON array of objects.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	steps := t.Steps
+	if steps == nil {
+		steps = []Step{}
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(steps)
+}
+
+// SetTrace turns on or off the recording of the steps of the parsing. The
+// steps already recorded are dropped.
+func (p *Parser) SetTrace(on bool) {
+	p.trace = nil
+	if on {
+		p.trace = &Trace{}
+	}
+}
+
+// Trace returns the steps recorded since SetTrace or Reset, or nil if the
+// tracing is off. The operands are recorded when the parsing of the
+// options ends.
+func (p *Parser) Trace() *Trace {
+	return p.trace
+}
+
+// record adds a step for the bytes from start to end of the argument word
+// at index i, if the tracing is on.
+func (p *Parser) record(kind Kind, i, start, end int, secret bool) {
+	if p.trace == nil {
+		return
+	}
+	s := Step{Index: i, Start: start, End: end, Kind: kind,
+		Text: p.args[i][start:end]}
+	if kind != KindTerminator && kind != KindOperand {
+		s.Option = p.optName
+	}
+	if secret {
+		s.Text = "***"
+	}
+	p.trace.Steps = append(p.trace.Steps, s)
+}
+
+// recordOperands adds a step for each argument word left, if the tracing
+// is on.
+func (p *Parser) recordOperands() {
+	if p.trace == nil {
+		return
+	}
+	for i := p.optIndex; i < len(p.args); i++ {
+		p.record(KindOperand, i, 0, len(p.args[i]), false)
+	}
+}
@@ -0,0 +1,111 @@
+package getopt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	args := []string{"test", "-abfoo", "--output=x", "-p", "pw", "-n", "1", "2",
+		"--", "-c", "file"}
+	p := NewParser(args, "ab:o:p:n:c")
+	p.Long("output", 'o')
+	p.SetSecret('p')
+	p.SetArgs('n', 1, 3)
+	p.SetTrace(true)
+	for o, e := p.Option(); o != EndOption; o, e = p.Option() {
+		if e != nil {
+			t.Fatalf("option %q: %v", o, e)
+		}
+	}
+	want := []Step{
+		{1, 1, 2, KindOption, "-a", "a"},
+		{1, 2, 3, KindOption, "-b", "b"},
+		{1, 3, 6, KindAttached, "-b", "foo"},
+		{2, 0, 8, KindOption, "--output", "--output"},
+		{2, 9, 10, KindAttached, "--output", "x"},
+		{3, 1, 2, KindOption, "-p", "p"},
+		{4, 0, 2, KindSeparate, "-p", "***"},
+		{5, 1, 2, KindOption, "-n", "n"},
+		{6, 0, 1, KindSeparate, "-n", "1"},
+		{7, 0, 1, KindSeparate, "-n", "2"},
+		{8, 0, 2, KindTerminator, "", "--"},
+		{9, 0, 2, KindOperand, "", "-c"},
+		{10, 0, 4, KindOperand, "", "file"},
+	}
+	steps := p.Trace().Steps
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d: %v", len(steps), len(want), steps)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d is %v, want %v", i, steps[i], want[i])
+		}
+	}
+	var b strings.Builder
+	p.Trace().WriteJSON(&b)
+	if !strings.Contains(b.String(), `"kind": "attached"`) {
+		t.Errorf("the JSON has no attached step:\n%s", b.String())
+	}
+	p.Reset()
+	if len(p.Trace().Steps) != 0 {
+		t.Errorf("Reset kept %d steps", len(p.Trace().Steps))
+	}
+}
+
+func TestDebugArgs(t *testing.T) {
+	var stderr strings.Builder
+	p := NewParser([]string{"test", "--debug-args", "-vo", "out", "in"}, "vo:")
+	p.SetStandard(Standard{Stderr: &stderr, DebugArgs: true})
+	var seen []rune
+	for o, _ := p.Option(); o != EndOption; o, _ = p.Option() {
+		seen = append(seen, o)
+	}
+	if string(seen) != "vo" {
+		t.Errorf("options returned %q", string(seen))
+	}
+	want := `INDEX  BYTES  KIND      OPTION        TEXT
+1      0-12   option    --debug-args  "--debug-args"
+2      1-2    option    -v            "v"
+2      2-3    option    -o            "o"
+3      0-3    separate  -o            "out"
+4      0-2    operand                 "in"
+`
+	if stderr.String() != want {
+		t.Errorf("the trace is\n%s\nwant\n%s", stderr.String(), want)
+	}
+	if strings.Contains(p.Usage(), "debug-args") {
+		t.Errorf("the usage shows --debug-args:\n%s", p.Usage())
+	}
+}
+
+func TestCloneDebugArgs(t *testing.T) {
+	var stderr strings.Builder
+	p := NewParser([]string{"test"}, "vo:")
+	p.SetStandard(Standard{Stderr: &stderr, DebugArgs: true})
+	q, r := p.Clone(), p.Clone()
+	q.ResetArgs([]string{"test", "--debug-args", "-v"})
+	r.ResetArgs([]string{"test", "-o", "out"})
+	if o, _ := q.Option(); o != 'v' {
+		t.Fatalf("first clone: Option() returned %q", o)
+	}
+	for o, _ := r.Option(); o != EndOption; o, _ = r.Option() {
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("the second clone wrote the trace:\n%s", stderr.String())
+	}
+	if steps := r.Trace().Steps; len(steps) != 2 || steps[0].Option != "-o" {
+		t.Errorf("the second clone traced %v", steps)
+	}
+	q.ResetArgs([]string{"test", "-v"})
+	for o, _ := q.Option(); o != EndOption; o, _ = q.Option() {
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Reset kept --debug-args:\n%s", stderr.String())
+	}
+	for o, _ := p.Option(); o != EndOption; o, _ = p.Option() {
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("the parser wrote the trace:\n%s", stderr.String())
+	}
+}
@@ -0,0 +1,226 @@
+package getopt
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxColumn is the largest width of the column of option names in Usage.
+// The description of longer names starts on the next line.
+const maxColumn = 30
+
+// SetSynopsis sets the text shown by Usage after the name of the program and
+// the options, like "FILE...".
+func (p *Parser) SetSynopsis(s string) {
+	p.synopsis = s
+}
+
+// unquote returns the name enclosed in back quotes in the help text, and the
+// text without the back quotes, like flag.UnquoteUsage.
+func unquote(help string) (string, string) {
+	i := strings.IndexByte(help, '`')
+	if i >= 0 {
+		j := strings.IndexByte(help[i+1:], '`')
+		if j >= 0 {
+			name := help[i+1 : i+1+j]
+			return name, help[:i] + name + help[i+1+j+1:]
+		}
+	}
+	return "", help
+}
+
+// program returns the name of the program.
+func (p *Parser) program() string {
+	if p.std != nil && p.std.Program != "" {
+		return p.std.Program
+	}
+	if len(p.args) == 0 {
+		return ""
+	}
+	return filepath.Base(p.args[0])
+}
+
+// usageLine is a line of the help text: the names and the description.
+type usageLine struct {
+	names, help string
+}
+
+// usageOption returns the line describing the option o.
+func (p *Parser) usageOption(o rune) usageLine {
+	c := p.catalog()
+	s := p.specs[o]
+	if s == nil {
+		s = &spec{}
+	}
+	var names []string
+	short := ""
+	if p.shorts != "" {
+		short = p.shorts[:1]
+	}
+	if short != "" && o < 0x7f && strings.IndexRune(p.opts, o) >= 0 {
+		names = append(names, short+string(o))
+	}
+	var aliases []string
+	for a, d := range p.specs {
+		if short != "" && d.alias == o && !d.deprecated && !d.hidden {
+			aliases = append(aliases, short+string(a))
+		}
+	}
+	sort.Strings(aliases)
+	names = append(names, aliases...)
+	indent := ""
+	if len(names) == 0 {
+		indent = "    "
+	}
+	if p.long != "" {
+		for _, l := range p.longs {
+			if l.o == o && !l.deprecated {
+				names = append(names, p.long+l.name)
+			}
+		}
+	}
+	line := indent + strings.Join(names, ", ")
+	arg, help := unquote(s.help)
+	if arg == "" && s.choices != nil {
+		arg = "{" + strings.Join(s.choices, ",") + "}"
+	}
+	if arg == "" {
+		arg = c.Message("usage.arg")
+	}
+	if s.nargs {
+		for i := 0; i < s.min; i++ {
+			line += " " + arg
+		}
+		if s.max == s.min+1 {
+			line += " [" + arg + "]"
+		} else if s.max > s.min {
+			line += " [" + arg + "...]"
+		}
+	} else if p.takesArgs(o) {
+		line += " " + arg
+	}
+	var notes []string
+	if help != "" {
+		notes = append(notes, help)
+	}
+	if s.hasValue && !s.secret {
+		notes = append(notes, c.Message("usage.default", s.value))
+	}
+	for _, r := range p.required {
+		if r == o {
+			notes = append(notes, c.Message("usage.required"))
+			break
+		}
+	}
+	if s.limit > 0 {
+		notes = append(notes, c.Message("usage.indirect"))
+	}
+	return usageLine{line, strings.Join(notes, " ")}
+}
+
+// options returns the options shown by Usage, in the order of the options
+// string, followed by the options having only long names. Without short
+// options, only the options having long names are shown.
+func (p *Parser) options() []rune {
+	var options []rune
+	known := make(map[rune]bool)
+	add := func(o rune) {
+		if known[o] || o == helpOption || o == versionOption ||
+			o == debugOption ||
+			(o == 'h' && p.std != nil && p.std.short) {
+			return
+		}
+		known[o] = true
+		if s, found := p.specs[o]; !found || !s.hidden {
+			options = append(options, o)
+		}
+	}
+	for _, o := range p.opts {
+		if o != ':' && p.shorts != "" {
+			add(o)
+		}
+	}
+	if p.long != "" {
+		for _, l := range p.longs {
+			add(l.o)
+		}
+	}
+	return options
+}
+
+// writeLines writes the lines in two columns to b.
+func writeLines(b *strings.Builder, lines []usageLine) {
+	width := 0
+	for _, l := range lines {
+		if len(l.names) > width && len(l.names) <= maxColumn {
+			width = len(l.names)
+		}
+	}
+	for _, l := range lines {
+		b.WriteString("  ")
+		b.WriteString(l.names)
+		if l.help != "" {
+			if len(l.names) > width {
+				b.WriteString("\n  ")
+				b.WriteString(strings.Repeat(" ", width))
+			} else {
+				b.WriteString(strings.Repeat(" ", width-len(l.names)))
+			}
+			b.WriteString("  ")
+			b.WriteString(l.help)
+		}
+		b.WriteByte('\n')
+	}
+}
+
+// Usage returns the help text of the program: a synopsis, the options with
+// their descriptions (see SetHelp) and the keys of the operands (see Keys).
+// The hidden options and the deprecated aliases are left out.
+func (p *Parser) Usage() string {
+	c := p.catalog()
+	var b strings.Builder
+	synopsis := c.Message("usage.synopsis")
+	if p.synopsis != "" {
+		synopsis += " " + p.synopsis
+	}
+	b.WriteString(c.Message("usage.line", p.program(), synopsis))
+	b.WriteByte('\n')
+	var lines []usageLine
+	for _, o := range p.options() {
+		lines = append(lines, p.usageOption(o))
+	}
+	if p.std != nil && p.long != "" {
+		help := "    " + p.long + "help"
+		if p.std.short && p.shorts != "" {
+			help = p.shorts[:1] + "h, " + p.long + "help"
+		}
+		lines = append(lines, usageLine{help, c.Message("usage.help")},
+			usageLine{"    " + p.long + "version", c.Message("usage.version")})
+	}
+	if len(lines) > 0 {
+		b.WriteByte('\n')
+		b.WriteString(c.Message("usage.options"))
+		b.WriteByte('\n')
+		writeLines(&b, lines)
+	}
+	if p.keys != nil && len(p.keys.order) > 0 {
+		lines = lines[:0]
+		for _, name := range p.keys.order {
+			k := p.keys.keys[name]
+			arg, help := unquote(k.help)
+			if arg == "" {
+				arg = c.Message("usage.arg")
+			}
+			if v := k.value.String(); v != "" {
+				help += " " + c.Message("usage.default", v)
+			}
+			lines = append(lines, usageLine{name + "=" + arg, strings.TrimSpace(help)})
+		}
+		b.WriteByte('\n')
+		b.WriteString(c.Message("usage.operands"))
+		b.WriteByte('\n')
+		writeLines(&b, lines)
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package getopt
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Validator checks an argument given to an option. It returns nil if the
+// value is acceptable, or an error describing the problem.
+type Validator func(value string) error
+
+// PathCheck selects the checks done by the Path validator.
+type PathCheck int
+
+const (
+	// PathExists requires the path to exist.
+	PathExists PathCheck = 1 << iota
+	// PathDir requires the path to be a directory.
+	PathDir
+	// PathWritable requires the path to have a write permission bit set.
+	PathWritable
+)
+
+var (
+	// ErrChoice is returned when a value is not among the allowed choices.
+	ErrChoice = errors.New("getopt: value not among the choices")
+	// ErrRange is returned when a value is not a number in the allowed range.
+	ErrRange = errors.New("getopt: value out of range")
+	// ErrMatch is returned when a value doesn't match a regular expression.
+	ErrMatch = errors.New("getopt: value doesn't match")
+	// ErrNotDir is returned when a path is not a directory.
+	ErrNotDir = errors.New("getopt: not a directory")
+	// ErrReadOnly is returned when a path is not writable.
+	ErrReadOnly = errors.New("getopt: not writable")
+)
+
+// A ValueError records an option argument rejected by a Validator.
+type ValueError struct {
+	Option string // the option, like "-x"
+	Value  string // the rejected argument, or its source if indirect
+	Err    error  // the error returned by the validator
+}
+
+func (e *ValueError) Error() string {
+	return "getopt: invalid value " + strconv.Quote(e.Value) + " for option " +
+		e.Option + ": " + strings.TrimPrefix(e.Err.Error(), "getopt: ")
+}
+
+// Unwrap returns the error returned by the validator.
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// Choice returns a Validator accepting only the given choices.
+func Choice(choices ...string) Validator {
+	return func(value string) error {
+		for _, c := range choices {
+			if value == c {
+				return nil
+			}
+		}
+		return ErrChoice
+	}
+}
+
+// Range returns a Validator accepting only numbers between min and max,
+// inclusive. The value is parsed by strconv.ParseFloat; "NaN" and the
+// infinities are always rejected.
+func Range(min, max float64) Validator {
+	return func(value string) error {
+		f, e := strconv.ParseFloat(value, 64)
+		if e != nil || math.IsNaN(f) || math.IsInf(f, 0) || f < min || f > max {
+			return ErrRange
+		}
+		return nil
+	}
+}
+
+// Match returns a Validator accepting only the values matched entirely by
+// the regular expression re.
+func Match(re *regexp.Regexp) Validator {
+	whole := regexp.MustCompile(`\A(?:` + re.String() + `)\z`)
+	return func(value string) error {
+		if !whole.MatchString(value) {
+			return ErrMatch
+		}
+		return nil
+	}
+}
+
+// fsName converts the path name to a name suitable for fs.FS. The name is
+// cleaned and the leading slashes are removed, so that absolute names can be
+// used with os.DirFS("/").
+func fsName(name string) string {
+	name = strings.TrimLeft(path.Clean(name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// Path returns a Validator checking the path given as value against fsys.
+// The value is cleaned and its leading slashes are removed before looking
+// it up. The check is a combination of PathExists, PathDir and PathWritable.
+// Without PathExists or PathDir, a missing path is accepted. As fs.FS is
+// read-only, PathWritable only looks at the permission bits.
+func Path(fsys fs.FS, check PathCheck) Validator {
+	return func(value string) error {
+		name := fsName(value)
+		if !fs.ValidPath(name) {
+			return fs.ErrInvalid
+		}
+		info, e := fs.Stat(fsys, name)
+		if e != nil {
+			if errors.Is(e, fs.ErrNotExist) && check&(PathExists|PathDir) == 0 {
+				return nil
+			}
+			return e
+		}
+		if check&PathDir != 0 && !info.IsDir() {
+			return ErrNotDir
+		}
+		if check&PathWritable != 0 && info.Mode().Perm()&0222 == 0 {
+			return ErrReadOnly
+		}
+		return nil
+	}
+}
+
+// SetValidator adds validators to the option o. Each argument of o is
+// checked by the validators in the order they were added. If a validator
+// fails, Option returns o and a *ValueError.
+func (p *Parser) SetValidator(o rune, v ...Validator) {
+	s := p.spec(o)
+	s.validators = append(s.validators, v...)
+}
+
+// SetChoices restricts the arguments of the option o to the given choices.
+func (p *Parser) SetChoices(o rune, choices ...string) {
+	p.spec(o).choices = choices
+	p.SetValidator(o, Choice(choices...))
+}
+
+// validate checks the arguments of the option o.
+func (p *Parser) validate(o rune, name string) error {
+	s, found := p.specs[o]
+	if !found {
+		return nil
+	}
+	for i, a := range p.optArgs {
+		for _, v := range s.validators {
+			if e := v(a); e != nil {
+				if i < len(p.optSrcs) && p.optSrcs[i] != "" {
+					// don't show the contents of an indirect argument
+					a = p.optSrcs[i]
+				}
+				return &ValueError{name, a, e}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package getopt
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestValidators(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/config": {Data: []byte("x"), Mode: 0444},
+		"var/log":    {Mode: fs.ModeDir | 0755},
+	}
+	tests := []struct {
+		v     Validator
+		value string
+		err   error
+	}{
+		{Choice("red", "green"), "green", nil},
+		{Choice("red", "green"), "blue", ErrChoice},
+		{Range(1, 10), "10", nil},
+		{Range(1, 10), "0.5", ErrRange},
+		{Range(1, 10), "ten", ErrRange},
+		{Range(math.Inf(-1), math.Inf(1)), "NaN", ErrRange},
+		{Range(math.Inf(-1), math.Inf(1)), "-Inf", ErrRange},
+		{Match(regexp.MustCompile(`[a-z]+`)), "abc", nil},
+		{Match(regexp.MustCompile(`[a-z]+`)), "abc1", ErrMatch},
+		{Match(regexp.MustCompile(`a|ab`)), "ab", nil},
+		{Path(fsys, PathExists), "/etc/config", nil},
+		{Path(fsys, PathExists), "etc/missing", fs.ErrNotExist},
+		{Path(fsys, PathDir), "./var/log", nil},
+		{Path(fsys, PathDir), "etc/config", ErrNotDir},
+		{Path(fsys, PathWritable), "var/log", nil},
+		{Path(fsys, PathWritable), "etc/config", ErrReadOnly},
+		{Path(fsys, PathWritable), "etc/new", nil},
+	}
+	for _, test := range tests {
+		if e := test.v(test.value); !errors.Is(e, test.err) {
+			t.Errorf("%q: validator returned %v, not %v", test.value, e, test.err)
+		}
+	}
+}
+
+func TestValueError(t *testing.T) {
+	args := []string{"test", "-c", "red", "-n", "11", "-c", "blue"}
+	p := NewParser(args, "c:n:")
+	p.SetChoices('c', "red", "green")
+	p.SetValidator('n', Range(0, 10))
+	var errs []error
+	for {
+		o, e := p.Option()
+		if o == EndOption {
+			break
+		}
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("%d errors returned, not 2", len(errs))
+	}
+	var v *ValueError
+	if !errors.As(errs[0], &v) || v.Option != "-n" || v.Value != "11" ||
+		!errors.Is(errs[0], ErrRange) {
+		t.Errorf("first error: %v", errs[0])
+	}
+	if !errors.As(errs[1], &v) || v.Option != "-c" || v.Value != "blue" ||
+		!errors.Is(errs[1], ErrChoice) {
+		t.Errorf("second error: %v", errs[1])
+	}
+}
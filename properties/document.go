@@ -0,0 +1,279 @@
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// node is a logical line of a document: a blank line, a comment line or
+// a key-value pair.
+type node struct {
+	text    string // the natural lines as read, with their end-of-lines
+	entry   bool   // whether the node holds a key-value pair
+	key     string // the key, for a key-value pair
+	value   string // the value, for a key-value pair
+	prefix  string // the text before the value, if it can be reused
+	changed bool   // whether text must be written again
+}
+
+// Document represents a property file which can be edited without losing
+// its layout. It holds the sequence of the logical lines of the file:
+// blank lines, comment lines and key-value pairs, each with its text as
+// read. When the document is stored, the lines not changed by Set,
+// Delete or InsertAfter are written back byte for byte.
+type Document struct {
+	nodes []node
+	eol   string
+}
+
+// Load reads the lines from r and appends them to the document. The lines
+// are parsed like by the Load method of Table, but their text is kept.
+// The method returns the number of key-value pairs loaded and any error
+// encountered.
+func (d *Document) Load(r io.Reader) (int, error) {
+	data, e := io.ReadAll(r)
+	count := 0
+	for len(data) > 0 {
+		n := logicalLength(data)
+		if d.eol == "" {
+			d.eol = lineEnd(data[:n])
+		}
+		b, _ := loadBytes(bufio.NewReader(bytes.NewReader(data[:n])))
+		x := node{text: string(data[:n])}
+		if len(b) > 0 && b[0] != '#' && b[0] != '!' {
+			key, i := unescape(b, true)
+			value, _ := unescape(b[i:], false)
+			x.entry, x.key, x.value = true, key, value
+			if separated(b[:i]) && i <= firstLength(data[:n]) {
+				start := len(data[:n]) - len(bytes.TrimLeft(data[:n], " \t\f"))
+				x.prefix = string(data[:start+i])
+			}
+			count += 1
+		}
+		d.nodes = append(d.nodes, x)
+		data = data[n:]
+	}
+	return count, e
+}
+
+// LoadString loads a document using the given string as input.
+// The method returns the number of key-value pairs loaded and any error
+// encountered.
+func (d *Document) LoadString(s string) (int, error) {
+	return d.Load(strings.NewReader(s))
+}
+
+// separated reports whether the text k of a key, as delimited by unescape,
+// ends with a separator which isn't escaped.
+func separated(k []byte) bool {
+	n := len(k)
+	if n == 0 || !(isSpace(rune(k[n-1])) || isDelimiter(rune(k[n-1]))) {
+		return false
+	}
+	slashes := n - 1 - len(bytes.TrimRight(k[:n-1], "\\"))
+	return slashes%2 == 0
+}
+
+// naturalLength returns the length of the first natural line in p and the
+// length of its end-of-line.
+func naturalLength(p []byte) (int, int) {
+	i := bytes.IndexAny(p, "\r\n")
+	if i < 0 {
+		return len(p), 0
+	}
+	if p[i] == '\r' && i+1 < len(p) && p[i+1] == '\n' {
+		return i + 2, 2
+	}
+	return i + 1, 1
+}
+
+// logicalLength returns the length of the first logical line in p,
+// including the natural lines it continues on and the last end-of-line.
+func logicalLength(p []byte) int {
+	n := 0
+	for first := true; n < len(p); first = false {
+		size, eol := naturalLength(p[n:])
+		line := bytes.TrimLeft(p[n:n+size-eol], " \t\f")
+		n += size
+		if first && (len(line) == 0 || line[0] == '#' || line[0] == '!') {
+			break
+		}
+		slashes := len(line) - len(bytes.TrimRight(line, "\\"))
+		if slashes%2 == 0 {
+			break
+		}
+	}
+	return n
+}
+
+// firstLength returns the number of bytes of the logical line p which
+// come from its first natural line, not counting the leading white space,
+// the end-of-line and the backslash escaping it.
+func firstLength(p []byte) int {
+	size, eol := naturalLength(p)
+	line := bytes.TrimLeft(p[:size-eol], " \t\f")
+	if size < len(p) {
+		return len(line) - 1
+	}
+	return len(line)
+}
+
+// lineEnd returns the end-of-line of the last natural line in p, or the
+// empty string if there's none.
+func lineEnd(p []byte) string {
+	switch {
+	case bytes.HasSuffix(p, []byte("\r\n")):
+		return "\r\n"
+	case bytes.HasSuffix(p, []byte("\n")):
+		return "\n"
+	case bytes.HasSuffix(p, []byte("\r")):
+		return "\r"
+	}
+	return ""
+}
+
+// find returns the index of the last key-value pair having the given key,
+// or -1 if there's none.
+func (d *Document) find(key string) int {
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		if d.nodes[i].entry && d.nodes[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lookup searches the value associated with key. If the key appears
+// several times, the last value is returned, like by the Load method of
+// Table.
+// The method returns the value (or the empty string) and a boolean
+// indicating whether the value was found or not.
+func (d *Document) Lookup(key string) (string, bool) {
+	if i := d.find(key); i >= 0 {
+		return d.nodes[i].value, true
+	}
+	return "", false
+}
+
+// Get returns the value associated with the string key, or the empty string
+// if the key isn't found.
+func (d *Document) Get(key string) string {
+	value, _ := d.Lookup(key)
+	return value
+}
+
+// Set associates key with value. If key is already present, the value of
+// its last key-value pair is replaced, keeping the text of the key and of
+// the separator when possible. Otherwise, the pair is added at the end.
+func (d *Document) Set(key string, value string) {
+	if i := d.find(key); i >= 0 {
+		d.nodes[i].value = value
+		d.nodes[i].changed = true
+		return
+	}
+	d.nodes = append(d.nodes, node{entry: true, key: key, value: value,
+		changed: true})
+}
+
+// InsertAfter associates key with value in a key-value pair placed after
+// the last pair having the key after, or at the end if after isn't found.
+// Any pair already holding key is deleted first.
+func (d *Document) InsertAfter(after, key, value string) {
+	d.Delete(key)
+	i := d.find(after)
+	if i < 0 {
+		i = len(d.nodes) - 1
+	}
+	x := node{entry: true, key: key, value: value, changed: true}
+	d.nodes = append(d.nodes[:i+1], append([]node{x}, d.nodes[i+1:]...)...)
+}
+
+// Delete removes all the key-value pairs having the given key. The comment
+// lines before them are kept. If the key isn't present, calling this
+// function does nothing.
+func (d *Document) Delete(key string) {
+	nodes := d.nodes[:0]
+	for _, x := range d.nodes {
+		if !x.entry || x.key != key {
+			nodes = append(nodes, x)
+		}
+	}
+	d.nodes = nodes
+}
+
+// Keys returns the distinct keys of the document, in the order of their
+// first appearance.
+func (d *Document) Keys() []string {
+	t := make(map[string]bool)
+	s := []string{}
+	for _, x := range d.nodes {
+		if x.entry && !t[x.key] {
+			t[x.key] = true
+			s = append(s, x.key)
+		}
+	}
+	return s
+}
+
+// Table returns a new property table holding the key-value pairs of the
+// document, in the same order.
+func (d *Document) Table() *Table {
+	p := new(Table)
+	for _, x := range d.nodes {
+		if x.entry {
+			p.set(x.key, x.value)
+		}
+	}
+	return p
+}
+
+// Store writes the document to w. The lines not changed are written as
+// they were read. The key-value pairs changed are written again on a single
+// line: the indentation and the text of the key and of the separator are
+// kept if they were on the first natural line, otherwise the pair is written
+// like by the Store method of Table. The ascii parameter has the same meaning
+// as for the Store method of Table and applies only to the text written
+// again.
+// The method returns the number of key-value pairs written and any error
+// encountered.
+func (d *Document) Store(w io.Writer, ascii bool) (int, error) {
+	count := 0
+	eol := d.eol
+	if eol == "" {
+		eol = "\n"
+	}
+	for i, x := range d.nodes {
+		text := []byte(x.text)
+		if x.changed {
+			end := lineEnd(text)
+			if end == "" && (x.text == "" || i < len(d.nodes)-1) {
+				end = eol
+			}
+			if x.prefix != "" {
+				text = append([]byte(x.prefix), escapeValue(x.value, ascii)...)
+			} else {
+				text = escape(x.key, x.value, ascii)
+			}
+			text = append(text, end...)
+		} else if i < len(d.nodes)-1 && lineEnd(text) == "" {
+			text = append(text, eol...)
+		}
+		if _, e := w.Write(text); e != nil {
+			return count, e
+		}
+		if x.entry {
+			count += 1
+		}
+	}
+	return count, nil
+}
+
+// String returns the text of the document, with the changed key-value pairs
+// written in UTF-8.
+func (d *Document) String() string {
+	var b strings.Builder
+	d.Store(&b, false)
+	return b.String()
+}
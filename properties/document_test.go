@@ -0,0 +1,59 @@
+package properties
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	var d Document
+	text := "# Settings\r\n\r\nname = old name\r\n  list = a, \\\r\n" +
+		"       b\r\n! note \\\r\nempty\r\nkey\\ \\: : value\r\nname=shadowed\r\nlast:end"
+	n, e := d.LoadString(text)
+	if n != 6 || e != nil {
+		t.Fatal("LoadString() returned ", n, e)
+	}
+	if s := d.String(); s != text {
+		t.Error("String() returned ", s)
+	}
+	if d.Get("list") != "a, b" || d.Get("name") != "shadowed" || d.Get("key :") != "value" {
+		t.Error("Get() returned ", d.Get("list"), d.Get("name"), d.Get("key :"))
+	}
+	d.Set("list", "c")
+	d.Set("key :", " spaced #value")
+	d.Set("last", "€")
+	d.Delete("name")
+	d.InsertAfter("empty", "new key", "new")
+	d.Set("added", "x")
+	want := "# Settings\r\n\r\n  list = c\r\n! note \\\r\nempty\r\nnew\\ key=new\r\n" +
+		"key\\ \\: : \\ spaced \\#value\r\nlast:\\u20ac\r\nadded=x\r\n"
+	var b strings.Builder
+	if n, _ := d.Store(&b, true); n != 6 || b.String() != want {
+		t.Errorf("Store() wrote %d pairs, %q, want %q", n, b.String(), want)
+	}
+	k := d.Keys()
+	if len(k) != 6 || k[0] != "list" || k[1] != "empty" || k[2] != "new key" {
+		t.Error("Keys() returned ", k)
+	}
+	if p := d.Table(); p.Get("new key") != "new" || p.Get("empty") != "" {
+		t.Error("Table() returned ", p)
+	}
+}
+
+func TestDocumentEmpty(t *testing.T) {
+	var d Document
+	n, e := d.LoadString("name   =   \nflag\nkey\\ =\n")
+	if n != 3 || e != nil {
+		t.Fatal("LoadString() returned ", n, e)
+	}
+	if v, ok := d.Lookup("name"); v != "" || !ok {
+		t.Error("Lookup() returned ", v, ok)
+	}
+	d.Set("name", "v")
+	d.Set("flag", "on")
+	d.Set("key ", "w")
+	want := "name   =   v\nflag=on\nkey\\ =w\n"
+	if s := d.String(); s != want {
+		t.Errorf("String() returned %q, want %q", s, want)
+	}
+}
@@ -0,0 +1,151 @@
+package properties
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format tells how the key-value pairs of a table are written by the
+// StoreFormat and SaveFormat methods. The zero value writes the pairs of
+// the table, without the 'defaults' table, in the order of its keys and in
+// UTF-8.
+type Format struct {
+	// ASCII has the same meaning as the ascii parameter of Store.
+	ASCII bool
+	// Less, if not nil, sorts the keys. It reports whether the key a must
+	// be written before the key b. See Lexical and Natural.
+	Less func(a, b string) bool
+	// Defaults includes the pairs of the 'defaults' table, and of its own
+	// 'defaults' table and so on, which are not hidden by a pair having
+	// the same key.
+	Defaults bool
+	// Java writes the output of java.util.Properties.store: the keys and
+	// values are escaped like by Java, with uppercase '\uXXXX' sequences,
+	// and SaveFormat writes a date line after the comments. Unless Less is
+	// set, the keys are sorted by UTF16, like since Java 18. If ASCII is
+	// true, the output is the one of store(OutputStream), in ISO-8859-1;
+	// otherwise it's the one of store(Writer) with a UTF-8 writer. An
+	// empty comments string stands for null, and "\n" is the line
+	// separator.
+	Java bool
+	// Now gives the time of the date line in Java mode. If nil, time.Now
+	// is used.
+	Now func() time.Time
+}
+
+// Lexical reports whether a sorts before b, comparing the bytes of the
+// strings.
+func Lexical(a, b string) bool {
+	return a < b
+}
+
+// Natural reports whether a sorts before b, comparing the runs of decimal
+// digits by their numeric value and the rest by bytes, so that "item.9"
+// sorts before "item.10". If the numbers are equal, the one with less
+// leading zeros comes first.
+func Natural(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			m, n := digits(a[i:]), digits(b[j:])
+			x := strings.TrimLeft(a[i:i+m], "0")
+			y := strings.TrimLeft(b[j:j+n], "0")
+			if len(x) != len(y) {
+				return len(x) < len(y)
+			}
+			if x != y {
+				return x < y
+			}
+			if m != n {
+				return m < n
+			}
+			i, j = i+m, j+n
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i, j = i+1, j+1
+	}
+	return len(a)-i < len(b)-j
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// digits returns the number of decimal digits at the beginning of s.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	return n
+}
+
+// StoreFormat writes the key-value pairs of this property table to w like
+// Store, in the format f.
+// The method returns the number of key-value pairs written and any error
+// encountered.
+func (p *Table) StoreFormat(w io.Writer, f Format) (int, error) {
+	keys := p.order
+	if f.Defaults {
+		keys = p.Keys()
+	}
+	less := f.Less
+	if less == nil && f.Java {
+		less = UTF16
+	}
+	if less != nil {
+		keys = append([]string(nil), keys...)
+		sort.SliceStable(keys, func(i, j int) bool {
+			return less(keys[i], keys[j])
+		})
+	}
+	count := 0
+	eol := []byte("\n")
+	for _, key := range keys {
+		value, _ := p.Lookup(key)
+		line := escape(key, value, f.ASCII)
+		if f.Java {
+			line = append(escapeJava(key, true, f.ASCII), '=')
+			line = append(line, escapeJava(value, false, f.ASCII)...)
+		}
+		if _, e := w.Write(line); e != nil {
+			return count, e
+		}
+		if _, e := w.Write(eol); e != nil {
+			return count, e
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// SaveFormat writes the comments like Save, then the key-value pairs of
+// this property table in the format f, like StoreFormat. In Java mode, the
+// comments and the date line are written like by Java.
+// The method returns the number of key-value pairs written and any error
+// encountered.
+func (p *Table) SaveFormat(w io.Writer, comments string, f Format) (int, error) {
+	if f.Java {
+		var b []byte
+		if comments != "" {
+			b = escapeJavaComments(comments, f.ASCII)
+		}
+		if _, e := w.Write(append(b, javaDateLine(f.Now)...)); e != nil {
+			return 0, e
+		}
+		return p.StoreFormat(w, f)
+	}
+	eol := []byte("\n")
+	if _, e := w.Write(escapeComment(comments, f.ASCII)); e != nil {
+		return 0, e
+	}
+	if _, e := w.Write(eol); e != nil {
+		return 0, e
+	}
+	return p.StoreFormat(w, f)
+}
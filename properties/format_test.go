@@ -0,0 +1,36 @@
+package properties
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNatural(t *testing.T) {
+	keys := []string{"item.10", "item.9", "item", "item.09", "a2b10", "a2b9",
+		"item.1.2", "Item"}
+	sort.Slice(keys, func(i, j int) bool { return Natural(keys[i], keys[j]) })
+	want := "Item a2b9 a2b10 item item.1.2 item.9 item.09 item.10"
+	if s := strings.Join(keys, " "); s != want {
+		t.Errorf("the keys are sorted as %q, want %q", s, want)
+	}
+}
+
+func TestStoreFormat(t *testing.T) {
+	defaults := new(Table)
+	defaults.LoadString("item.2=d2\nitem.10=d10\nname=default")
+	p := NewTableDefaults(defaults)
+	p.Set("name", "table")
+	p.Set("item.1", "t1")
+	var b strings.Builder
+	n, e := p.StoreFormat(&b, Format{Less: Natural, Defaults: true})
+	want := "item.1=t1\nitem.2=d2\nitem.10=d10\nname=table\n"
+	if n != 4 || e != nil || b.String() != want {
+		t.Errorf("StoreFormat() wrote %d pairs, %q, %v", n, b.String(), e)
+	}
+	b.Reset()
+	p.SaveFormat(&b, "sorted", Format{Less: Lexical})
+	if want = "#sorted\nitem.1=t1\nname=table\n"; b.String() != want {
+		t.Errorf("SaveFormat() wrote %q", b.String())
+	}
+}
@@ -0,0 +1,111 @@
+package properties
+
+import (
+	"time"
+	"unicode/utf16"
+)
+
+// javaDate is the layout of Java's Date.toString, used for the date line
+// written by Properties.store.
+const javaDate = "Mon Jan 02 15:04:05 MST 2006"
+
+const hexDigits = "0123456789ABCDEF"
+
+// appendJavaUnicode appends to b the '\uXXXX' sequences of the UTF-16
+// code units of r, with uppercase digits like Java.
+func appendJavaUnicode(b []byte, r rune) []byte {
+	for _, u := range utf16.Encode([]rune{r}) {
+		b = append(b, '\\', 'u', hexDigits[u>>12], hexDigits[u>>8&0xf],
+			hexDigits[u>>4&0xf], hexDigits[u&0xf])
+	}
+	return b
+}
+
+// escapeJava returns s escaped like by the saveConvert method of Java's
+// Properties: all the spaces of a key, but only the leading one of a value,
+// are escaped, like '\\', '\t', '\n', '\r', '\f', '=', ':', '#' and '!'.
+// If unicode is true, the other characters out of 0x20-0x7e are written
+// as '\uXXXX' sequences of UTF-16 code units.
+func escapeJava(s string, key, unicode bool) []byte {
+	var b []byte
+	for x, r := range []rune(s) {
+		switch {
+		case r > 61 && r < 127 && r != '\\':
+			b = append(b, byte(r))
+		case r == '\\':
+			b = append(b, '\\', '\\')
+		case r == ' ':
+			if x == 0 || key {
+				b = append(b, '\\')
+			}
+			b = append(b, ' ')
+		case r == '\t':
+			b = append(b, '\\', 't')
+		case r == '\n':
+			b = append(b, '\\', 'n')
+		case r == '\r':
+			b = append(b, '\\', 'r')
+		case r == '\f':
+			b = append(b, '\\', 'f')
+		case r == '=', r == ':', r == '#', r == '!':
+			b = append(b, '\\', byte(r))
+		case (r < 0x20 || r > 0x7e) && unicode:
+			b = appendJavaUnicode(b, r)
+		default:
+			b = append(b, string(r)...)
+		}
+	}
+	return b
+}
+
+// escapeJavaComments returns the comment lines written by Java's
+// Properties.store for comments, with the final end-of-line: each line
+// starts with '#' unless it already starts with '#' or '!', and the
+// characters above 0xff are written as '\uXXXX' sequences. The other
+// characters are encoded in ISO-8859-1 if latin1 is true, in UTF-8
+// otherwise.
+func escapeJavaComments(comments string, latin1 bool) []byte {
+	b := []byte{'#'}
+	runes := []rune(comments)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r > 0xff:
+			b = appendJavaUnicode(b, r)
+		case r == '\n' || r == '\r':
+			b = append(b, '\n')
+			if r == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+				i++
+			}
+			if i+1 == len(runes) || (runes[i+1] != '#' && runes[i+1] != '!') {
+				b = append(b, '#')
+			}
+		case latin1:
+			b = append(b, byte(r))
+		default:
+			b = append(b, string(r)...)
+		}
+	}
+	return append(b, '\n')
+}
+
+// UTF16 reports whether a sorts before b, comparing their UTF-16 code
+// units like Java's String.compareTo. This is the order of the keys
+// written by Properties.store since Java 18.
+func UTF16(a, b string) bool {
+	x, y := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
+
+// javaDateLine returns the date line written by Java's Properties.store.
+func javaDateLine(now func() time.Time) []byte {
+	if now == nil {
+		now = time.Now
+	}
+	return []byte("#" + now().Format(javaDate) + "\n")
+}
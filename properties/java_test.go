@@ -0,0 +1,119 @@
+package properties
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// javaNow is the time of the date line of the vectors in testdata/java,
+// given to Gen.java by the java.properties.date system property.
+func javaNow() time.Time {
+	return time.Date(2024, time.February, 29, 13, 5, 0, 0, time.UTC)
+}
+
+func TestJavaVectors(t *testing.T) {
+	inputs, _ := filepath.Glob(filepath.Join("testdata", "java", "*.properties"))
+	if len(inputs) == 0 {
+		t.Fatal("no vectors in testdata/java")
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(input, ".properties")
+		data, e := os.ReadFile(input)
+		if e != nil {
+			t.Fatal(e)
+		}
+		p := new(Table)
+		p.LoadString(string(data))
+		comments, e := os.ReadFile(name + ".comments")
+		if e != nil && !errors.Is(e, fs.ErrNotExist) {
+			t.Fatal(e)
+		}
+		for _, suffix := range []string{".latin1.out", ".utf8.out"} {
+			want, e := os.ReadFile(name + suffix)
+			if e != nil {
+				t.Fatal(e)
+			}
+			var b bytes.Buffer
+			f := Format{Java: true, ASCII: suffix == ".latin1.out", Now: javaNow}
+			p.SaveFormat(&b, string(comments), f)
+			if !bytes.Equal(b.Bytes(), want) {
+				t.Errorf("%s: wrote\n%q\nwant\n%q", name+suffix, b.Bytes(), want)
+			}
+		}
+	}
+}
+
+// TestJavaGen checks the vectors against the output of Gen.java, if a
+// java command is found. If PROPERTIES_JAVA is set, as on a CI runner with
+// a JDK, a missing or too old java fails the test instead of skipping it.
+func TestJavaGen(t *testing.T) {
+	skip := t.Skipf
+	if os.Getenv("PROPERTIES_JAVA") != "" {
+		skip = t.Fatalf
+	}
+	java, e := exec.LookPath("java")
+	if e != nil {
+		skip("no java command found")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command(java, "-Djava.properties.date="+javaNow().Format(javaDate),
+		"Gen.java", dir)
+	cmd.Dir = filepath.Join("testdata", "java")
+	out, e := cmd.CombinedOutput()
+	var exit *exec.ExitError
+	if errors.As(e, &exit) && exit.ExitCode() == 2 {
+		skip("%s", out)
+	}
+	if e != nil {
+		t.Fatalf("Gen.java failed: %v\n%s", e, out)
+	}
+	vectors, _ := filepath.Glob(filepath.Join("testdata", "java", "*.out"))
+	for _, vector := range vectors {
+		want, _ := os.ReadFile(vector)
+		got, e := os.ReadFile(filepath.Join(dir, filepath.Base(vector)))
+		if e != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s: Java wrote\n%q\nwant\n%q", vector, got, want)
+		}
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"a", "b", true},
+		{"ab", "a", false},
+		{"a", "ab", true},
+		{"\U0001F600", "！", true},
+		{"！", "\U0001F600", false},
+		{"x", "x", false},
+	}
+	for _, test := range tests {
+		if got := UTF16(test.a, test.b); got != test.want {
+			t.Errorf("UTF16(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestStoreJava(t *testing.T) {
+	p := new(Table)
+	p.Set(" k", " v  w")
+	var b strings.Builder
+	p.StoreFormat(&b, Format{Java: true})
+	if want := "\\ k=\\ v  w\n"; b.String() != want {
+		t.Errorf("StoreFormat() wrote %q, want %q", b.String(), want)
+	}
+	b.Reset()
+	p.SaveFormat(&b, "", Format{Java: true, Now: javaNow})
+	if want := "#Thu Feb 29 13:05:00 UTC 2024\n\\ k=\\ v  w\n"; b.String() != want {
+		t.Errorf("SaveFormat() wrote %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,122 @@
+package properties
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Lookup gives the value of a reference ${prefix:arg} for the argument
+// arg, when registered under prefix in a Resolver. It returns an error
+// wrapping ErrNotFound if there's no value for arg, so that the fallback
+// of the reference is used. Any other error stops the resolution; the
+// lookups doing slow operations are expected to enforce their own
+// timeouts.
+type Lookup func(arg string) (string, error)
+
+// Register makes the resolver use l for the references ${prefix:arg}. The
+// references whose key starts with a prefix not registered are searched
+// in the table.
+func (r *Resolver) Register(prefix string, l Lookup) {
+	if r.Lookups == nil {
+		r.Lookups = make(map[string]Lookup)
+	}
+	r.Lookups[prefix] = l
+}
+
+// DefaultLookups returns the lookups "env", "file", "base64", "date" and
+// "sys", using the environment and the files of the process and the
+// current time.
+func DefaultLookups() map[string]Lookup {
+	return map[string]Lookup{
+		"env":    Env(nil),
+		"file":   File(nil),
+		"base64": Base64,
+		"date":   Date(nil),
+		"sys":    Sys,
+	}
+}
+
+// Env returns a lookup giving the value of an environment variable, found
+// by getenv, or by os.LookupEnv if getenv is nil.
+func Env(getenv func(string) (string, bool)) Lookup {
+	if getenv == nil {
+		getenv = os.LookupEnv
+	}
+	return func(name string) (string, error) {
+		if value, found := getenv(name); found {
+			return value, nil
+		}
+		return "", ErrNotFound
+	}
+}
+
+// File returns a lookup giving the content of a file, without the final
+// end-of-line, like for the secrets mounted as files. The file is read
+// from fsys, where a leading '/' is ignored, or from the operating system
+// if fsys is nil. A file which doesn't exist gives an error wrapping
+// ErrNotFound.
+func File(fsys fs.FS) Lookup {
+	return func(name string) (string, error) {
+		var data []byte
+		var e error
+		if fsys == nil {
+			data, e = os.ReadFile(name)
+		} else {
+			data, e = fs.ReadFile(fsys, strings.TrimPrefix(path.Clean(name), "/"))
+		}
+		if e != nil {
+			if errors.Is(e, fs.ErrNotExist) {
+				return "", ErrNotFound
+			}
+			return "", e
+		}
+		s := strings.TrimSuffix(string(data), "\n")
+		return strings.TrimSuffix(s, "\r"), nil
+	}
+}
+
+// Base64 is a lookup decoding its argument, written in the standard base64
+// encoding, with or without padding.
+func Base64(s string) (string, error) {
+	encoding := base64.StdEncoding
+	if !strings.HasSuffix(s, "=") {
+		encoding = base64.RawStdEncoding
+	}
+	data, e := encoding.DecodeString(s)
+	return string(data), e
+}
+
+// Date returns a lookup giving the time returned by now, or by time.Now if
+// now is nil, formatted with the layout given as argument (see time.Format),
+// or with time.RFC3339 if the argument is empty.
+func Date(now func() time.Time) Lookup {
+	if now == nil {
+		now = time.Now
+	}
+	return func(layout string) (string, error) {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return now().Format(layout), nil
+	}
+}
+
+// Sys is a lookup giving facts about the host: "hostname", "os" and
+// "arch", as known by the Go runtime.
+func Sys(name string) (string, error) {
+	switch name {
+	case "hostname":
+		return os.Hostname()
+	case "os":
+		return runtime.GOOS, nil
+	case "arch":
+		return runtime.GOARCH, nil
+	}
+	return "", ErrNotFound
+}
@@ -0,0 +1,67 @@
+package properties
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLookups(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "EMPTY": ""}
+	fsys := fstest.MapFS{"run/secrets/db": {Data: []byte("s3cret\n")}}
+	now := func() time.Time { return time.Date(2024, 2, 29, 13, 5, 0, 0, time.UTC) }
+	p := new(Table)
+	p.LoadString(`cache=${env:HOME}/.cache
+		shell=${env:SHELL:-/bin/sh}
+		empty=[${env:EMPTY:-none}]
+		password=${file:/run/secrets/db}
+		token=${base64:${encoded}}
+		encoded=aGVsbG8
+		stamp=build-${date:20060102}
+		platform=${sys:os}/${sys:arch}
+		bad=${base64:!!}
+		custom=${upper:shout}
+		plain=${not.a.lookup:x}`)
+	r := Resolver{Table: p}
+	r.Register("env", Env(func(name string) (string, bool) {
+		value, found := env[name]
+		return value, found
+	}))
+	r.Register("file", File(fsys))
+	r.Register("base64", Base64)
+	r.Register("date", Date(now))
+	r.Register("sys", Sys)
+	r.Register("upper", func(s string) (string, error) { return strings.ToUpper(s), nil })
+	p.Set("not.a.lookup:x", "table")
+	tests := []struct{ key, want string }{
+		{"cache", "/home/me/.cache"},
+		{"shell", "/bin/sh"},
+		{"empty", "[]"},
+		{"password", "s3cret"},
+		{"token", "hello"},
+		{"stamp", "build-20240229"},
+		{"platform", runtime.GOOS + "/" + runtime.GOARCH},
+		{"custom", "SHOUT"},
+		{"plain", "table"},
+	}
+	for _, test := range tests {
+		if s, e := r.Get(test.key); s != test.want || e != nil {
+			t.Errorf("Get(%q) returned %q, %v", test.key, s, e)
+		}
+	}
+	var v *ValueError
+	if _, e := r.Get("bad"); !errors.As(e, &v) || v.Key != "bad" {
+		t.Error("Get() returned ", e)
+	}
+	r.Strict = true
+	p.Set("missing", "${file:nothing}")
+	if _, e := r.Get("missing"); !errors.Is(e, ErrUndefined) {
+		t.Error("Get() returned ", e)
+	}
+	if len(DefaultLookups()) != 5 {
+		t.Error("DefaultLookups() returned ", DefaultLookups())
+	}
+}
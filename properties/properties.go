@@ -6,6 +6,7 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"sort"
 	"strings"
 	"unicode/utf16"
 	"unicode/utf8"
@@ -92,10 +93,10 @@ func unescapeRune(p []byte) (rune, int) {
 		r = utf8.RuneError
 	}
 	// here, n = 6 (the length of a '\uxxxx' sequence)
-	if utf16.IsSurrogate(r) {
+	if 0xd800 <= r && r < 0xdc00 {
 		q := r
 		r, size = unescapeRune(p[6:])
-		if size != 6 || !utf16.IsSurrogate(r) {
+		if size != 6 || r < 0xdc00 || r >= 0xe000 {
 			return utf8.RuneError, 6
 		}
 		r = utf16.DecodeRune(q, r)
@@ -142,6 +143,7 @@ func unescape(p []byte, split bool) (string, int) {
 					p = p[size:]
 					n += size
 				}
+				return b.String(), n
 			}
 		}
 		b.WriteRune(r)
@@ -206,14 +208,27 @@ func loadBytes(r *bufio.Reader) ([]byte, error) {
 
 // Table represents a property table. It contains:
 // - a map of key-value pairs;
+// - the keys of the map, in the order they were added;
 // - a pointer to a 'defaults' property table.
 // The 'defaults' table is searched if a property key isn't found within
 // the first one.
 type Table struct {
 	data     map[string]string
+	order    []string
 	defaults *Table
 }
 
+// set associates key with value, appending key to the order if it's new.
+func (p *Table) set(key string, value string) {
+	if p.data == nil {
+		p.data = make(map[string]string)
+	}
+	if _, found := p.data[key]; !found {
+		p.order = append(p.order, key)
+	}
+	p.data[key] = value
+}
+
 // Load reads the key-value pairs from r. Reading is done line by line. There
 // are natural lines and logical lines. A natural line is a character sequence
 // ending either by the standard end-of-line ('\n', '\r' or '\r\n') or by the
@@ -275,21 +290,20 @@ type Table struct {
 // backslash is silently dropped. Escapes are not necessary for single and
 // double quotes, but single and double quote characters preceded by a backslash
 // yield single and double quote characters, respectively.
+// The keys are added in the order of the input. A key already present keeps
+// its position, only its value is replaced.
 // The method returns the number of key-value pairs loaded and any error
 // encountered.
 func (p *Table) Load(r io.Reader) (int, error) {
 	var reader = bufio.NewReader(r)
 	count := 0
 	done := false
-	if p.data == nil {
-		p.data = make(map[string]string)
-	}
 	for !done {
 		b, e := loadBytes(reader)
 		if len(b) > 0 && b[0] != '#' && b[0] != '!' {
 			key, i := unescape(b, true)
 			value, _ := unescape(b[i:], false)
-			p.data[key] = value
+			p.set(key, value)
 			count += 1
 		}
 		if e != nil {
@@ -311,10 +325,16 @@ func (p *Table) LoadString(s string) (int, error) {
 }
 
 func escape(key, value string, ascii bool) []byte {
+	b := escapeKey(key, ascii)
+	b = append(b, '=')
+	return append(b, escapeValue(value, ascii)...)
+}
+
+// escapeKey returns the key as written by Store, without the separator.
+func escapeKey(key string, ascii bool) []byte {
 	var b bytes.Buffer
 	var buffer [12]byte
-	var r rune
-	for _, r = range key {
+	for _, r := range key {
 		size := 0
 		if ascii {
 			size = escapeRune(buffer[:], r)
@@ -335,8 +355,14 @@ func escape(key, value string, ascii bool) []byte {
 		}
 		b.Write(buffer[:size])
 	}
-	b.WriteRune('=')
-	r, _ = utf8.DecodeRuneInString(value)
+	return b.Bytes()
+}
+
+// escapeValue returns the value as written by Store after the separator.
+func escapeValue(value string, ascii bool) []byte {
+	var b bytes.Buffer
+	var buffer [12]byte
+	r, _ := utf8.DecodeRuneInString(value)
 	if isSpace(r) || isDelimiter(r) {
 		b.WriteByte('\\')
 	}
@@ -396,8 +422,9 @@ func escapeComment(text string, ascii bool) []byte {
 // this method.
 // If ascii is true, then any rune lesser than 0x20 or greater than 0x7e
 // is converted to its '\uxxxx'  escape sequence(s).
-// Every key-value pair in the table is then written out, one per line. For
-// each pair the key is written, then an ASCII '=', then the associated value.
+// Every key-value pair in the table is then written out, one per line, in
+// the order of the keys (see Keys). For each pair the key is written, then
+// an ASCII '=', then the associated value.
 // For the key, all space characters are written with a preceding '\\'
 // character. For the value, only the leading white space characters are
 // written with a preceding '\\' character. The key and value characters '#',
@@ -406,18 +433,7 @@ func escapeComment(text string, ascii bool) []byte {
 // The method returns the number of key-value pairs written and any error
 // encountered.
 func (p *Table) Store(w io.Writer, ascii bool) (int, error) {
-	count := 0
-	eol := []byte("\n")
-	for key, value := range p.data {
-		if _, e := w.Write(escape(key, value, ascii)); e != nil {
-			return count, e
-		}
-		if _, e := w.Write(eol); e != nil {
-			return count, e
-		}
-		count += 1
-	}
-	return count, nil
+	return p.StoreFormat(w, Format{ASCII: ascii})
 }
 
 // Save writes the key-value pairs of this property table to w in a format
@@ -431,14 +447,7 @@ func (p *Table) Store(w io.Writer, ascii bool) (int, error) {
 // The method returns the number of key-value pairs written and any error
 // encountered.
 func (p *Table) Save(w io.Writer, comments string, ascii bool) (int, error) {
-	eol := []byte("\n")
-	if _, e := w.Write(escapeComment(comments, ascii)); e != nil {
-		return 0, e
-	}
-	if _, e := w.Write(eol); e != nil {
-		return 0, e
-	}
-	return p.Store(w, ascii)
+	return p.SaveFormat(w, comments, Format{ASCII: ascii})
 }
 
 // SaveString returns the text form of the property table and any error
@@ -451,13 +460,13 @@ func (p *Table) SaveString(comments string, ascii bool) (string, error) {
 }
 
 // String returns the (UTF-8) text representation of the property table (not
-// including the key-value pairs of the 'defaults' table). The text can be
-// then reused by LoadString.
+// including the key-value pairs of the 'defaults' table), in the order of
+// the keys. The text can be then reused by LoadString.
 func (p *Table) String() string {
 	var b strings.Builder
 	eol := []byte("\n")
-	for key, value := range p.data {
-		b.Write(escape(key, value, false))
+	for _, key := range p.order {
+		b.Write(escape(key, p.data[key], false))
 		b.Write(eol)
 	}
 	return b.String()
@@ -469,16 +478,23 @@ func (p *Table) String() string {
 func NewTableDefaults(defaults *Table) *Table {
 	return &Table{
 		map[string]string{},
+		nil,
 		defaults,
 	}
 }
 
 // NewTable creates and initializes a property table using the given data.
 // The new table takes ownership of data, which shouldn't be used after
-// this call.
+// this call. As a map has no order, the keys are put in lexical order.
 func NewTable(data map[string]string) *Table {
+	order := make([]string, 0, len(data))
+	for key := range data {
+		order = append(order, key)
+	}
+	sort.Strings(order)
 	return &Table{
 		data,
+		order,
 		nil,
 	}
 }
@@ -508,24 +524,33 @@ func (p *Table) Get(key string) string {
 }
 
 // Set associates key with value in this table. If key is already present,
-// then the associated value is replaced.
+// then the associated value is replaced and the key keeps its position.
+// Otherwise, the key is added after the others.
 func (p *Table) Set(key string, value string) {
-	if p.data == nil {
-		p.data = make(map[string]string)
-	}
-	p.data[key] = value
+	p.set(key, value)
 }
 
 // Delete removes the key and the associated value from this table. If the key
-// isn't present, calling this function does nothing.
+// isn't present, calling this function does nothing. If the key is set again
+// later, it's added after the others.
 func (p *Table) Delete(key string) {
+	if _, found := p.data[key]; !found {
+		return
+	}
 	delete(p.data, key)
+	for i, k := range p.order {
+		if k == key {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
 }
 
 // Clear deletes all the key-value pairs in this table. It doesn't alter the
 // pairs in the 'defaults' table.
 func (p *Table) Clear() {
 	p.data = make(map[string]string)
+	p.order = nil
 }
 
 // ClearAll deletes all the key-value pairs from this table and from the
@@ -538,21 +563,19 @@ func (p *Table) ClearAll() {
 }
 
 // Keys returns all the distinct keys in this table and the 'defaults' table.
+// The keys of this table come first, in the order they were loaded or set,
+// followed by the other keys of the 'defaults' table, in its own order.
 func (p *Table) Keys() []string {
 	t := make(map[string]bool)
+	s := []string{}
 	for p != nil {
-		for k := range p.data {
+		for _, k := range p.order {
 			if !t[k] {
 				t[k] = true
+				s = append(s, k)
 			}
 		}
 		p = p.defaults
 	}
-	s := make([]string, len(t))
-	i := 0
-	for k := range t {
-		s[i] = k
-		i++
-	}
 	return s
 }
@@ -30,6 +30,19 @@ func TestLoadString(t *testing.T) {
 	}
 }
 
+func TestLoadEmptyValue(t *testing.T) {
+	var p Table
+	p.LoadString(`first\ key = first value
+		empty\ key =
+		sixth\ key`)
+	if v, ok := p.Lookup("empty key"); v != "" || !ok {
+		t.Error(`p.Lookup("empty key") != "", true`)
+	}
+	if v, ok := p.Lookup("sixth key"); v != "" || !ok {
+		t.Error(`p.Lookup("sixth key") != "", true`)
+	}
+}
+
 func TestKeys(t *testing.T) {
 	var p Table
 	p.LoadString(`1\ firstKey=firstValue
@@ -127,3 +140,37 @@ func TestDefaults(t *testing.T) {
 		t.Error("SaveString() returned ", s)
 	}
 }
+
+func TestOrder(t *testing.T) {
+	var p Table
+	p.LoadString("zeta=1\nalpha=2\nmu=3\n")
+	p.Set("beta", "4")
+	p.Delete("alpha")
+	p.Set("alpha", "5")
+	p.LoadString("mu=6\nomega=7\n")
+	want := "zeta=1\nmu=6\nbeta=4\nalpha=5\nomega=7\n"
+	if s := p.String(); s != want {
+		t.Error("String() returned ", s)
+	}
+	if s, _ := p.SaveString("", false); s != "\n"+want {
+		t.Error("SaveString() returned ", s)
+	}
+	q := NewTableDefaults(NewTable(map[string]string{"b": "1", "a": "2", "mu": "3"}))
+	q.Set("z", "4")
+	q.Set("mu", "5")
+	k := q.Keys()
+	if len(k) != 4 || k[0] != "z" || k[1] != "mu" || k[2] != "a" || k[3] != "b" {
+		t.Error("Keys() returned ", k)
+	}
+}
+
+func TestSurrogates(t *testing.T) {
+	var p Table
+	p.LoadString("smile=\\uD83D\\uDE00\nlow=\\ude00x\nhigh=\\ud83dx\n")
+	if p.Get("smile") != "\U0001F600" {
+		t.Errorf("p.Get(\"smile\") = %q", p.Get("smile"))
+	}
+	if p.Get("low") != "\uFFFDx" || p.Get("high") != "\uFFFDx" {
+		t.Errorf("the lone surrogates are loaded as %q, %q", p.Get("low"), p.Get("high"))
+	}
+}
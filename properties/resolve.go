@@ -0,0 +1,198 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrCycle is returned by a Resolver when a value refers to itself,
+	// directly or through other values.
+	ErrCycle = errors.New("properties: reference cycle")
+	// ErrUndefined is returned by a strict Resolver when a reference names
+	// a key which isn't found and has no fallback.
+	ErrUndefined = errors.New("properties: undefined reference")
+)
+
+// Resolver replaces the references to other keys in the values of a
+// table. A reference is written ${key}, and is replaced by the value of
+// the key, searched like by Lookup, itself resolved. A fallback may be
+// given, like in ${key:-fallback}; it's used if the key isn't found. The
+// key and the fallback may hold references too, like in ${dir.${mode}}.
+// A '$' is written "$$", so that "$${key}" gives "${key}". A '$' not
+// followed by '{' or '$' and a reference without its closing '}' are kept
+// as they are. A reference like ${env:HOME} is given by the lookup
+// registered with the prefix "env", if any, without being resolved.
+type Resolver struct {
+	Table   *Table            // the table holding the values
+	Strict  bool              // whether an undefined reference is an error
+	Lookups map[string]Lookup // the lookups, by prefix (see Register)
+}
+
+// Get returns the value of key with the references replaced. If the key
+// isn't found, the error wraps ErrNotFound. A reference to a key which
+// isn't found, without a fallback, is replaced by the empty string or, if
+// r.Strict is true, gives an error wrapping ErrUndefined. A reference
+// cycle gives an error wrapping ErrCycle. The errors are *ValueError.
+func (r *Resolver) Get(key string) (string, error) {
+	return r.get(key, make(map[string]string))
+}
+
+// get returns the value of key like Get. The cache holds the values of the
+// keys already resolved.
+func (r *Resolver) get(key string, cache map[string]string) (string, error) {
+	value, found := r.Table.Lookup(key)
+	if !found {
+		return "", &ValueError{key, "", ErrNotFound}
+	}
+	if s, found := cache[key]; found {
+		return s, nil
+	}
+	s, e := r.expand(value, []string{key}, cache)
+	if e != nil {
+		return "", &ValueError{key, value, e}
+	}
+	cache[key] = s
+	return s, nil
+}
+
+// Expand returns s with the references replaced, like Get. The errors
+// wrap ErrCycle or ErrUndefined.
+func (r *Resolver) Expand(s string) (string, error) {
+	return r.expand(s, nil, make(map[string]string))
+}
+
+// Resolve returns a new table, without 'defaults' table, holding the keys
+// of r.Table and of its 'defaults' table, in the order given by Keys, with
+// their values resolved like by Get. It stops at the first error.
+func (r *Resolver) Resolve() (*Table, error) {
+	p := new(Table)
+	cache := make(map[string]string)
+	for _, key := range r.Table.Keys() {
+		value, e := r.get(key, cache)
+		if e != nil {
+			return nil, e
+		}
+		p.set(key, value)
+	}
+	return p, nil
+}
+
+// expand replaces the references in s. The stack holds the keys whose
+// values are being expanded, and the cache the values of the keys already
+// resolved, so that each key is resolved once.
+func (r *Resolver) expand(s string, stack []string,
+	cache map[string]string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) || (s[i+1] != '$' && s[i+1] != '{') {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] == '$' {
+			b.WriteByte('$')
+			continue
+		}
+		end := closing(s, i+1)
+		if end < 0 {
+			b.WriteString(s[i-1:])
+			break
+		}
+		value, e := r.reference(s[i+1:end], stack, cache)
+		if e != nil {
+			return "", e
+		}
+		b.WriteString(value)
+		i = end
+	}
+	return b.String(), nil
+}
+
+// reference returns the value of the reference ref, written without the
+// enclosing "${" and "}".
+func (r *Resolver) reference(ref string, stack []string,
+	cache map[string]string) (string, error) {
+	name, fallback := ref, ""
+	k := separator(ref)
+	if k >= 0 {
+		name, fallback = ref[:k], ref[k+2:]
+	}
+	name, e := r.expand(name, stack, cache)
+	if e != nil {
+		return "", e
+	}
+	for i, key := range stack {
+		if key == name {
+			chain := append(append([]string(nil), stack[i:]...), name)
+			return "", fmt.Errorf("%w: %s", ErrCycle, strings.Join(chain, " -> "))
+		}
+	}
+	if prefix, arg, ok := strings.Cut(name, ":"); ok && r.Lookups[prefix] != nil {
+		value, e := r.Lookups[prefix](arg)
+		if e == nil {
+			return value, nil
+		}
+		if !errors.Is(e, ErrNotFound) {
+			return "", e
+		}
+	} else if value, found := cache[name]; found {
+		return value, nil
+	} else if value, found := r.Table.Lookup(name); found {
+		stack = append(stack[:len(stack):len(stack)], name)
+		value, e = r.expand(value, stack, cache)
+		if e == nil {
+			cache[name] = value
+		}
+		return value, e
+	}
+	switch {
+	case k >= 0:
+		return r.expand(fallback, stack, cache)
+	case r.Strict:
+		return "", fmt.Errorf("%w: %s", ErrUndefined, name)
+	}
+	return "", nil
+}
+
+// separator returns the index of the ":-" separating the key from the
+// fallback in the reference ref, or -1 if there's none.
+func separator(ref string) int {
+	depth := 0
+	for i := 0; i+1 < len(ref); i++ {
+		switch {
+		case ref[i] == '$' && (ref[i+1] == '$' || ref[i+1] == '{'):
+			if ref[i+1] == '{' {
+				depth++
+			}
+			i++
+		case ref[i] == '}':
+			depth--
+		case ref[i] == ':' && ref[i+1] == '-' && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// closing returns the index in s of the '}' closing a reference whose
+// name starts at i, or -1 if it isn't closed.
+func closing(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && (s[i+1] == '$' || s[i+1] == '{'):
+			if s[i+1] == '{' {
+				depth++
+			}
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
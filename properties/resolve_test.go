@@ -0,0 +1,68 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	defaults := new(Table)
+	defaults.LoadString("base=/opt/app\nmode=prod")
+	p := NewTableDefaults(defaults)
+	p.LoadString(`logs=${base}/logs
+		dir.prod=${logs}/prod
+		current=${dir.${mode}}
+		port=${port.env:-${port.default:-8080}}
+		price=$$5 for $${base} and $ more ${
+		missing=[${nothing}]
+		loop.a=${loop.b}
+		loop.b=x${loop.a}`)
+	r := Resolver{Table: p}
+	tests := []struct{ key, want string }{
+		{"logs", "/opt/app/logs"},
+		{"current", "/opt/app/logs/prod"},
+		{"port", "8080"},
+		{"price", "$5 for ${base} and $ more ${"},
+		{"missing", "[]"},
+	}
+	for _, test := range tests {
+		if s, e := r.Get(test.key); s != test.want || e != nil {
+			t.Errorf("Get(%q) returned %q, %v", test.key, s, e)
+		}
+	}
+	_, e := r.Get("loop.a")
+	if !errors.Is(e, ErrCycle) || e.Error() != `properties: invalid value "${loop.b}"`+
+		` for key "loop.a": reference cycle: loop.a -> loop.b -> loop.a` {
+		t.Error("Get() returned ", e)
+	}
+	r.Strict = true
+	if _, e = r.Get("missing"); !errors.Is(e, ErrUndefined) {
+		t.Error("Get() returned ", e)
+	}
+	if _, e = r.Resolve(); e == nil {
+		t.Error("Resolve() returned no error")
+	}
+	p.Delete("missing")
+	p.Delete("loop.a")
+	p.Delete("loop.b")
+	q, e := r.Resolve()
+	if e != nil || q.Get("current") != "/opt/app/logs/prod" || q.Get("mode") != "prod" {
+		t.Error("Resolve() returned ", q, e)
+	}
+}
+
+func TestResolverChain(t *testing.T) {
+	p := new(Table)
+	for i := 0; i < 64; i++ {
+		p.Set(fmt.Sprint("k", i), fmt.Sprintf("${k%d}${k%d}", i+1, i+1))
+	}
+	p.Set("k64", "")
+	r := Resolver{Table: p, Strict: true}
+	if s, e := r.Get("k0"); s != "" || e != nil {
+		t.Error("Get() returned ", s, e)
+	}
+	if _, e := r.Resolve(); e != nil {
+		t.Error("Resolve() returned ", e)
+	}
+}
@@ -0,0 +1,60 @@
+// Gen writes the output of java.util.Properties.store for the vectors of
+// this directory. Each vector NAME is made of NAME.properties, the input
+// loaded with Properties.load, and the optional NAME.comments, the comments
+// given to store. The outputs are written to NAME.latin1.out, by
+// store(OutputStream), and to NAME.utf8.out, by store(Writer) with a UTF-8
+// writer, in the directory given as argument or in this directory. Run it
+// with Java 18 or later, on a system whose line separator is "\n", from
+// this directory:
+//
+//	java -Djava.properties.date="Thu Feb 29 13:05:00 UTC 2024" Gen.java [DIR]
+//
+// The outputs checked in were derived by hand from the sources of
+// Properties.store in Java 18 (store0, saveConvert and writeComments), as
+// no JDK was at hand; they have not been compared to the output of Gen
+// yet. TestJavaGen runs Gen in a temporary directory and compares the
+// outputs when a java command is found, and fails if they differ. Gen
+// exits with the status 2 before Java 18. With PROPERTIES_JAVA set, as on
+// a CI runner with a JDK, TestJavaGen fails instead of skipping when java
+// is missing or too old.
+import java.io.IOException;
+import java.io.OutputStream;
+import java.io.Reader;
+import java.io.Writer;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.DirectoryStream;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.Properties;
+
+public class Gen {
+    public static void main(String[] args) throws IOException {
+        if (Runtime.version().feature() < 18) {
+            System.err.println("Gen: Java 18 or later is required");
+            System.exit(2);
+        }
+        Path output = Paths.get(args.length > 0 ? args[0] : ".");
+        try (DirectoryStream<Path> dir = Files.newDirectoryStream(Paths.get("."), "*.properties")) {
+            for (Path input : dir) {
+                String name = input.getFileName().toString().replaceFirst("\\.properties$", "");
+                Properties p = new Properties();
+                try (Reader r = Files.newBufferedReader(input, StandardCharsets.UTF_8)) {
+                    p.load(r);
+                }
+                Path c = Paths.get(name + ".comments");
+                String comments = null;
+                if (Files.exists(c)) {
+                    comments = Files.readString(c, StandardCharsets.UTF_8);
+                }
+                try (OutputStream out = Files.newOutputStream(output.resolve(name + ".latin1.out"))) {
+                    p.store(out, comments);
+                }
+                try (Writer out = Files.newBufferedWriter(output.resolve(name + ".utf8.out"),
+                    StandardCharsets.UTF_8)) {
+                    p.store(out, comments);
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,269 @@
+package properties
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by the typed getters when the key is present
+// neither in the table nor in the 'defaults' table.
+var ErrNotFound = errors.New("properties: key not found")
+
+// ValueError is returned by the typed getters when the value of a key
+// can't be converted, and by a Resolver. Err is ErrNotFound,
+// strconv.ErrSyntax, strconv.ErrRange or an error wrapping ErrCycle or
+// ErrUndefined.
+type ValueError struct {
+	Key   string // the key
+	Value string // the value found
+	Err   error  // the reason of the failure
+}
+
+// Error returns a text naming the key and, if it was found, the value.
+func (e *ValueError) Error() string {
+	if e.Err == ErrNotFound {
+		return "properties: key " + strconv.Quote(e.Key) + " not found"
+	}
+	return "properties: invalid value " + strconv.Quote(e.Value) +
+		" for key " + strconv.Quote(e.Key) + ": " +
+		strings.TrimPrefix(e.Err.Error(), "properties: ")
+}
+
+// Unwrap returns the reason of the failure.
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// number returns the value of key, without the surrounding white space,
+// and calls parse with it. The errors of parse are returned as *ValueError.
+func (p *Table) number(key string, parse func(string) error) error {
+	value, found := p.Lookup(key)
+	if !found {
+		return &ValueError{key, "", ErrNotFound}
+	}
+	e := parse(strings.TrimSpace(value))
+	if e == nil {
+		return nil
+	}
+	var n *strconv.NumError
+	if errors.As(e, &n) {
+		e = n.Err
+	}
+	return &ValueError{key, value, e}
+}
+
+// Int returns the value of key as an int. The value may be written in
+// decimal, or in another base with the prefixes "0b", "0o" or "0x", like
+// in Go. The key is searched like by Lookup.
+func (p *Table) Int(key string) (int, error) {
+	var i int64
+	e := p.number(key, func(s string) (e error) {
+		i, e = strconv.ParseInt(s, 0, strconv.IntSize)
+		return e
+	})
+	return int(i), e
+}
+
+// Int64 returns the value of key as an int64, like Int.
+func (p *Table) Int64(key string) (int64, error) {
+	var i int64
+	e := p.number(key, func(s string) (e error) {
+		i, e = strconv.ParseInt(s, 0, 64)
+		return e
+	})
+	return i, e
+}
+
+// Uint returns the value of key as a uint, like Int.
+func (p *Table) Uint(key string) (uint, error) {
+	var u uint64
+	e := p.number(key, func(s string) (e error) {
+		u, e = strconv.ParseUint(s, 0, strconv.IntSize)
+		return e
+	})
+	return uint(u), e
+}
+
+// Float returns the value of key as a float64, written like in Go.
+func (p *Table) Float(key string) (float64, error) {
+	var f float64
+	e := p.number(key, func(s string) (e error) {
+		f, e = strconv.ParseFloat(s, 64)
+		return e
+	})
+	return f, e
+}
+
+// Bool returns the value of key as a bool. The values "true", "yes" and
+// "on" mean true, "false", "no" and "off" mean false, in any case.
+func (p *Table) Bool(key string) (bool, error) {
+	var b bool
+	e := p.number(key, func(s string) error {
+		switch strings.ToLower(s) {
+		case "true", "yes", "on":
+			b = true
+		case "false", "no", "off":
+			b = false
+		default:
+			return strconv.ErrSyntax
+		}
+		return nil
+	})
+	return b, e
+}
+
+// Duration returns the value of key as a time.Duration, written like
+// "1h30m" (see time.ParseDuration).
+func (p *Table) Duration(key string) (time.Duration, error) {
+	var d time.Duration
+	e := p.number(key, func(s string) (e error) {
+		if d, e = time.ParseDuration(s); e != nil {
+			return strconv.ErrSyntax
+		}
+		return nil
+	})
+	return d, e
+}
+
+// sizeUnits are the multipliers of the units accepted by Size.
+var sizeUnits = map[string]int64{
+	"": 1, "B": 1,
+	"kB": 1e3, "KB": 1e3, "MB": 1e6, "GB": 1e9,
+	"TB": 1e12, "PB": 1e15, "EB": 1e18,
+	"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40,
+	"PiB": 1 << 50, "EiB": 1 << 60,
+}
+
+// Size returns the value of key as a number of bytes. The value is a
+// decimal number followed by an optional unit: "B", the decimal units
+// "kB" (or "KB"), "MB", "GB", "TB", "PB" and "EB", and the binary units
+// "KiB", "MiB", "GiB", "TiB", "PiB" and "EiB". For example, "10MB" is
+// 10000000 and "512MiB" is 536870912.
+func (p *Table) Size(key string) (int64, error) {
+	var size int64
+	e := p.number(key, func(s string) error {
+		i := 0
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		unit, found := sizeUnits[strings.TrimSpace(s[i:])]
+		if i == 0 || !found {
+			return strconv.ErrSyntax
+		}
+		n, e := strconv.ParseInt(s[:i], 10, 64)
+		if e != nil {
+			return e
+		}
+		if n > math.MaxInt64/unit {
+			return strconv.ErrRange
+		}
+		size = n * unit
+		return nil
+	})
+	return size, e
+}
+
+// List returns the value of key split at the commas, with the white space
+// around the items removed. A comma or a backslash preceded by a backslash
+// is kept in the item. As Load removes the backslashes, they must be
+// doubled in the property file, like in "a\\,b, c". An empty value gives
+// an empty list.
+func (p *Table) List(key string) ([]string, error) {
+	value, found := p.Lookup(key)
+	if !found {
+		return nil, &ValueError{key, "", ErrNotFound}
+	}
+	if strings.TrimSpace(value) == "" {
+		return []string{}, nil
+	}
+	var list []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && i+1 < len(value):
+			i++
+			b.WriteByte(value[i])
+		case c == ',':
+			list = append(list, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(list, strings.TrimSpace(b.String())), nil
+}
+
+// GetInt returns the value of key as an int, like Int, or value if the key
+// isn't found or its value is invalid.
+func (p *Table) GetInt(key string, value int) int {
+	if i, e := p.Int(key); e == nil {
+		return i
+	}
+	return value
+}
+
+// GetInt64 returns the value of key as an int64, like Int64, or value if
+// the key isn't found or its value is invalid.
+func (p *Table) GetInt64(key string, value int64) int64 {
+	if i, e := p.Int64(key); e == nil {
+		return i
+	}
+	return value
+}
+
+// GetUint returns the value of key as a uint, like Uint, or value if the
+// key isn't found or its value is invalid.
+func (p *Table) GetUint(key string, value uint) uint {
+	if u, e := p.Uint(key); e == nil {
+		return u
+	}
+	return value
+}
+
+// GetFloat returns the value of key as a float64, like Float, or value if
+// the key isn't found or its value is invalid.
+func (p *Table) GetFloat(key string, value float64) float64 {
+	if f, e := p.Float(key); e == nil {
+		return f
+	}
+	return value
+}
+
+// GetBool returns the value of key as a bool, like Bool, or value if the
+// key isn't found or its value is invalid.
+func (p *Table) GetBool(key string, value bool) bool {
+	if b, e := p.Bool(key); e == nil {
+		return b
+	}
+	return value
+}
+
+// GetDuration returns the value of key as a time.Duration, like Duration,
+// or value if the key isn't found or its value is invalid.
+func (p *Table) GetDuration(key string, value time.Duration) time.Duration {
+	if d, e := p.Duration(key); e == nil {
+		return d
+	}
+	return value
+}
+
+// GetSize returns the value of key as a number of bytes, like Size, or
+// value if the key isn't found or its value is invalid.
+func (p *Table) GetSize(key string, value int64) int64 {
+	if size, e := p.Size(key); e == nil {
+		return size
+	}
+	return value
+}
+
+// GetList returns the value of key as a list, like List, or value if the
+// key isn't found.
+func (p *Table) GetList(key string, value []string) []string {
+	if list, e := p.List(key); e == nil {
+		return list
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package properties
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTyped(t *testing.T) {
+	defaults := new(Table)
+	defaults.LoadString("port = 8080 \ncolors = red, gr\\\\,een ,\\\\\\\\blue")
+	p := NewTableDefaults(defaults)
+	p.LoadString(`mask=0x1f
+		big=99999999999999999999
+		ratio=2.5
+		debug=Yes
+		quiet=off
+		timeout=1m30s
+		cache=512MiB
+		disk=10 MB
+		huge=9EiB
+		empty=`)
+	if i, e := p.Int("port"); i != 8080 || e != nil {
+		t.Error("Int() returned ", i, e)
+	}
+	if i, e := p.Int64("mask"); i != 31 || e != nil {
+		t.Error("Int64() returned ", i, e)
+	}
+	if u := p.GetUint("missing", 7); u != 7 {
+		t.Error("GetUint() returned ", u)
+	}
+	if f := p.GetFloat("ratio", 0); f != 2.5 {
+		t.Error("GetFloat() returned ", f)
+	}
+	if !p.GetBool("debug", false) || p.GetBool("quiet", true) {
+		t.Error("GetBool() returned wrong values")
+	}
+	if d := p.GetDuration("timeout", 0); d != 90*time.Second {
+		t.Error("GetDuration() returned ", d)
+	}
+	if n := p.GetSize("cache", 0); n != 512<<20 {
+		t.Error("GetSize() returned ", n)
+	}
+	if n := p.GetSize("disk", 0); n != 10000000 {
+		t.Error("GetSize() returned ", n)
+	}
+	if l := p.GetList("colors", nil); strings.Join(l, "|") != `red|gr,een|\blue` {
+		t.Errorf("GetList() returned %q", l)
+	}
+	if l, e := p.List("empty"); len(l) != 0 || e != nil {
+		t.Error("List() returned ", l, e)
+	}
+	_, e := p.Int("big")
+	var v *ValueError
+	if !errors.As(e, &v) || v.Key != "big" || !errors.Is(e, strconv.ErrRange) {
+		t.Error("Int() returned ", e)
+	}
+	if _, e = p.Size("huge"); !errors.Is(e, strconv.ErrRange) {
+		t.Error("Size() returned ", e)
+	}
+	_, e = p.Bool("ratio")
+	if e == nil || e.Error() != `properties: invalid value "2.5" for key "ratio": invalid syntax` {
+		t.Error("Bool() returned ", e)
+	}
+	if _, e = p.Duration("missing"); !errors.Is(e, ErrNotFound) {
+		t.Error("Duration() returned ", e)
+	}
+}
@@ -0,0 +1,350 @@
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrXML is returned by LoadXML when the document doesn't follow the
+	// Java properties DTD.
+	ErrXML = errors.New("properties: invalid XML properties document")
+	// ErrEncoding is returned by LoadXML and StoreXML for an encoding
+	// other than UTF-8, UTF-16, ISO-8859-1 and US-ASCII.
+	ErrEncoding = errors.New("properties: unsupported encoding")
+)
+
+// javaDTD is the document type declaration written by Java.
+const javaDTD = `DOCTYPE properties SYSTEM "http://java.sun.com/dtd/properties.dtd"`
+
+// encodingLimit returns the greatest rune which can be written in the
+// given encoding, or -1 if the encoding isn't supported.
+func encodingLimit(encoding string) rune {
+	switch strings.ToUpper(encoding) {
+	case "UTF-8", "UTF8", "UTF-16", "UTF16", "UTF-16BE", "UTF-16LE":
+		return utf8.MaxRune
+	case "ISO-8859-1", "ISO8859-1", "ISO_8859-1", "LATIN1":
+		return 0xff
+	case "US-ASCII", "ASCII":
+		return 0x7f
+	}
+	return -1
+}
+
+// isUTF16 reports whether encoding is one of the names of UTF-16.
+func isUTF16(encoding string) bool {
+	return strings.HasPrefix(strings.ToUpper(encoding), "UTF-16") ||
+		strings.EqualFold(encoding, "UTF16")
+}
+
+// charsetReader returns a reader converting the input from the given
+// encoding to UTF-8. The input in UTF-16 is converted by decodeUTF16
+// before reaching the decoder, so wide tells whether it was found.
+func charsetReader(encoding string, r io.Reader, wide bool) (io.Reader, error) {
+	limit := encodingLimit(encoding)
+	if limit < 0 || wide != isUTF16(encoding) {
+		return nil, fmt.Errorf("%w: %s", ErrEncoding, encoding)
+	}
+	if limit == utf8.MaxRune {
+		return r, nil
+	}
+	next := func(r *bufio.Reader) (rune, error) {
+		c, e := r.ReadByte()
+		if e == nil && rune(c) > limit {
+			e = fmt.Errorf("%w: byte 0x%02x in %s", ErrXML, c, encoding)
+		}
+		return rune(c), e
+	}
+	return &runeReader{r: bufio.NewReader(r), next: next}, nil
+}
+
+// decodeUTF16 returns a reader converting r to UTF-8 if it starts with a
+// UTF-16 byte order mark or with "<?" in UTF-16, and whether it does.
+func decodeUTF16(r io.Reader) (io.Reader, bool) {
+	br := bufio.NewReader(r)
+	b, _ := br.Peek(4)
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		order = binary.BigEndian
+		br.Discard(2)
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		order = binary.LittleEndian
+		br.Discard(2)
+	case bytes.Equal(b, []byte{0, '<', 0, '?'}):
+		order = binary.BigEndian
+	case bytes.Equal(b, []byte{'<', 0, '?', 0}):
+		order = binary.LittleEndian
+	default:
+		return br, false
+	}
+	unit := func(r *bufio.Reader) (rune, error) {
+		var b [2]byte
+		_, e := io.ReadFull(r, b[:])
+		return rune(order.Uint16(b[:])), e
+	}
+	next := func(r *bufio.Reader) (rune, error) {
+		u, e := unit(r)
+		if e != nil || !utf16.IsSurrogate(u) {
+			return u, e
+		}
+		if u < 0xdc00 {
+			if v, e := unit(r); e == nil && v >= 0xdc00 && v < 0xe000 {
+				return utf16.DecodeRune(u, v), nil
+			}
+		}
+		return 0, fmt.Errorf("%w: invalid UTF-16", ErrXML)
+	}
+	return &runeReader{r: br, next: next}, true
+}
+
+// runeReader converts an input to UTF-8, reading its runes with next.
+type runeReader struct {
+	r       *bufio.Reader
+	next    func(r *bufio.Reader) (rune, error)
+	pending []byte // the bytes of the last rune not read yet
+	buf     [utf8.UTFMax]byte
+}
+
+func (x *runeReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(x.pending) == 0 {
+			if n > 0 && x.r.Buffered() == 0 {
+				break
+			}
+			c, e := x.next(x.r)
+			if e != nil {
+				if n > 0 && e == io.EOF {
+					e = nil
+				}
+				return n, e
+			}
+			x.pending = x.buf[:utf8.EncodeRune(x.buf[:], c)]
+		}
+		k := copy(p[n:], x.pending)
+		x.pending = x.pending[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// isJavaDTD reports whether the directive d is the document type
+// declaration of the Java properties, with any quotes and white space.
+func isJavaDTD(d xml.Directive) bool {
+	s := strings.Join(strings.Fields(string(d)), " ")
+	return strings.ReplaceAll(s, "'", `"`) == javaDTD
+}
+
+// LoadXML reads the key-value pairs from r, in the XML format of Java's
+// Properties.loadFromXML: a root element <properties>, holding an optional
+// <comment> element followed by <entry key="..."> elements whose text is
+// the value. The encodings UTF-8, UTF-16, ISO-8859-1 and US-ASCII are
+// supported; a document in UTF-16 is recognized by its byte order mark or
+// by its XML declaration.
+// A document type declaration other than the one written by Java is
+// rejected, so that no external entity is ever resolved. The comment is
+// ignored.
+// The method returns the number of key-value pairs loaded and any error
+// encountered.
+func (p *Table) LoadXML(r io.Reader) (int, error) {
+	r, wide := decodeUTF16(r)
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(encoding string, r io.Reader) (io.Reader, error) {
+		return charsetReader(encoding, r, wide)
+	}
+	count := 0
+	depth := 0
+	var key, value string
+	comment := false
+	for {
+		t, e := d.Token()
+		if e == io.EOF {
+			return count, fmt.Errorf("%w: unexpected end of document", ErrXML)
+		}
+		if e != nil {
+			return count, e
+		}
+		switch t := t.(type) {
+		case xml.Directive:
+			if !isJavaDTD(t) {
+				return count, fmt.Errorf("%w: unsupported directive <!%s>", ErrXML, t)
+			}
+		case xml.StartElement:
+			name := t.Name.Local
+			switch {
+			case depth == 0 && name == "properties":
+			case depth == 1 && name == "comment" && !comment && count == 0:
+				comment = true
+			case depth == 1 && name == "entry":
+				found := false
+				for _, a := range t.Attr {
+					if a.Name.Local == "key" {
+						key, found = a.Value, true
+					}
+				}
+				if !found {
+					return count, fmt.Errorf("%w: entry without key", ErrXML)
+				}
+				value = ""
+			default:
+				return count, fmt.Errorf("%w: unexpected element <%s>", ErrXML, name)
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 1 && t.Name.Local == "entry" {
+				p.set(key, value)
+				count += 1
+			}
+			if depth == 0 {
+				return count, trailing(d)
+			}
+		case xml.CharData:
+			switch {
+			case depth == 2:
+				value += string(t)
+			case len(strings.TrimSpace(string(t))) > 0:
+				return count, fmt.Errorf("%w: unexpected text", ErrXML)
+			}
+		}
+	}
+}
+
+// trailing checks that only comments, processing instructions and white
+// space follow the root element.
+func trailing(d *xml.Decoder) error {
+	for {
+		t, e := d.Token()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			if len(strings.TrimSpace(string(t))) > 0 {
+				return fmt.Errorf("%w: unexpected text", ErrXML)
+			}
+		case xml.Comment, xml.ProcInst:
+		default:
+			return fmt.Errorf("%w: content after the root element", ErrXML)
+		}
+	}
+}
+
+// xmlWriter writes the text of an XML document in an encoding, replacing
+// the characters out of the encoding with character references.
+type xmlWriter struct {
+	w     *bufio.Writer
+	limit rune
+}
+
+// text writes s, escaping the markup characters, the quotes if attr is
+// true, and the carriage returns, so that they are read back unchanged.
+func (x *xmlWriter) text(s string, attr bool) error {
+	for _, r := range s {
+		switch {
+		case r == '&':
+			x.w.WriteString("&amp;")
+		case r == '<':
+			x.w.WriteString("&lt;")
+		case r == '>':
+			x.w.WriteString("&gt;")
+		case r == '"' && attr:
+			x.w.WriteString("&quot;")
+		case r == '\r' || (attr && (r == '\n' || r == '\t')):
+			fmt.Fprintf(x.w, "&#x%x;", r)
+		case r < 0x20 && r != '\n' && r != '\t', r == 0xfffe, r == 0xffff:
+			return fmt.Errorf("%w: character %U not allowed", ErrXML, r)
+		case r > x.limit:
+			fmt.Fprintf(x.w, "&#x%x;", r)
+		case x.limit == utf8.MaxRune:
+			x.w.WriteRune(r)
+		default:
+			x.w.WriteByte(byte(r))
+		}
+	}
+	return nil
+}
+
+// StoreXML writes the key-value pairs of this property table to w, in the
+// XML format of Java's Properties.storeToXML, which Java loads unchanged.
+// The pairs of the 'defaults' table are not written. If comments is not
+// empty, it's written in a <comment> element. The encoding is UTF-8 if
+// empty, or one of UTF-8, UTF-16, UTF-16BE, UTF-16LE, ISO-8859-1 and
+// US-ASCII; the characters out of the encoding are written as character
+// references. Like in Java, UTF-16 is written big-endian, after a byte
+// order mark.
+// The method returns the number of key-value pairs written and any error
+// encountered.
+func (p *Table) StoreXML(w io.Writer, comments string, encoding string) (int, error) {
+	if encoding == "" {
+		encoding = "UTF-8"
+	}
+	limit := encodingLimit(encoding)
+	if limit < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrEncoding, encoding)
+	}
+	var wide bytes.Buffer
+	out := w
+	if isUTF16(encoding) {
+		out = &wide
+	}
+	x := &xmlWriter{bufio.NewWriter(out), limit}
+	fmt.Fprintf(x.w, "<?xml version=\"1.0\" encoding=\"%s\" standalone=\"no\"?>\n", encoding)
+	x.w.WriteString("<!" + javaDTD + ">\n<properties>\n")
+	if comments != "" {
+		x.w.WriteString("<comment>")
+		if e := x.text(comments, false); e != nil {
+			return 0, e
+		}
+		x.w.WriteString("</comment>\n")
+	}
+	count := 0
+	for _, key := range p.order {
+		x.w.WriteString("<entry key=\"")
+		if e := x.text(key, true); e != nil {
+			return count, e
+		}
+		x.w.WriteString("\">")
+		if e := x.text(p.data[key], false); e != nil {
+			return count, e
+		}
+		x.w.WriteString("</entry>\n")
+		count += 1
+	}
+	x.w.WriteString("</properties>\n")
+	e := x.w.Flush()
+	if e == nil && out == &wide {
+		_, e = w.Write(encodeUTF16(wide.String(), encoding))
+	}
+	return count, e
+}
+
+// encodeUTF16 returns s in the given UTF-16 encoding: little-endian for
+// UTF-16LE, big-endian otherwise, with a byte order mark for UTF-16.
+func encodeUTF16(s string, encoding string) []byte {
+	var order binary.ByteOrder = binary.BigEndian
+	if strings.EqualFold(encoding, "UTF-16LE") {
+		order = binary.LittleEndian
+	}
+	units := utf16.Encode([]rune(s))
+	if !strings.EqualFold(encoding, "UTF-16BE") && !strings.EqualFold(encoding, "UTF-16LE") {
+		units = append([]uint16{0xfeff}, units...)
+	}
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		order.PutUint16(b[2*i:], u)
+	}
+	return b
+}
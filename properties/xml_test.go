@@ -0,0 +1,124 @@
+package properties
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+func TestStoreXML(t *testing.T) {
+	var p Table
+	p.Set("a<b", `x & "y" > z`)
+	p.Set("line", "one\r\ntwo\tthree")
+	p.Set("euro", "€ é")
+	var b strings.Builder
+	n, e := p.StoreXML(&b, "généré", "ISO-8859-1")
+	want := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\" standalone=\"no\"?>\n" +
+		"<!DOCTYPE properties SYSTEM \"http://java.sun.com/dtd/properties.dtd\">\n" +
+		"<properties>\n<comment>g\xe9n\xe9r\xe9</comment>\n" +
+		"<entry key=\"a&lt;b\">x &amp; \"y\" &gt; z</entry>\n" +
+		"<entry key=\"line\">one&#xd;\ntwo\tthree</entry>\n" +
+		"<entry key=\"euro\">&#x20ac; \xe9</entry>\n</properties>\n"
+	if n != 3 || e != nil || b.String() != want {
+		t.Fatalf("StoreXML() wrote %d pairs, %q, %v", n, b.String(), e)
+	}
+	var q Table
+	if n, e = q.LoadXML(strings.NewReader(b.String())); n != 3 || e != nil {
+		t.Fatal("LoadXML() returned ", n, e)
+	}
+	if q.String() != p.String() {
+		t.Errorf("LoadXML() loaded %q, want %q", q.String(), p.String())
+	}
+	p.Set("bell", "\a")
+	if _, e = p.StoreXML(&b, "", ""); !errors.Is(e, ErrXML) {
+		t.Error("StoreXML() returned ", e)
+	}
+	if _, e = p.StoreXML(&b, "", "EBCDIC"); !errors.Is(e, ErrEncoding) {
+		t.Error("StoreXML() returned ", e)
+	}
+}
+
+func TestLoadXML(t *testing.T) {
+	java := `<?xml version='1.0' encoding='US-ASCII'?>
+<!DOCTYPE properties SYSTEM 'http://java.sun.com/dtd/properties.dtd'>
+<!-- written by hand -->
+<properties version="1.0">
+  <comment>settings</comment>
+  <entry key="name">go &#x2013; <![CDATA[<utils>]]></entry>
+  <entry key="empty"/>
+</properties>
+`
+	var p Table
+	if n, e := p.LoadXML(strings.NewReader(java)); n != 2 || e != nil {
+		t.Fatal("LoadXML() returned ", n, e)
+	}
+	if v, found := p.Lookup("empty"); p.Get("name") != "go – <utils>" || !found || v != "" {
+		t.Errorf("LoadXML() loaded %q", p.String())
+	}
+	bad := []string{
+		`<?xml version="1.0"?><!DOCTYPE properties [<!ENTITY x SYSTEM "file:///etc/passwd">]>` +
+			`<properties><entry key="k">&x;</entry></properties>`,
+		`<properties><entry>v</entry></properties>`,
+		`<properties><entry key="k"><b/></entry></properties>`,
+		`<properties><entry key="k">v</entry><comment/></properties>`,
+		`<props/>`,
+		`<properties>text</properties>`,
+		`<properties><entry key="k">v</entry>`,
+		"<?xml version=\"1.0\" encoding=\"US-ASCII\"?><properties><entry key=\"k\">\xe9</entry></properties>",
+		`<properties/><properties/>`,
+		`<?xml version="1.0" encoding="UTF-16"?><properties/>`,
+	}
+	for _, s := range bad {
+		if _, e := new(Table).LoadXML(strings.NewReader(s)); e == nil {
+			t.Errorf("LoadXML(%q) returned no error", s)
+		}
+	}
+}
+
+func TestXMLUTF16(t *testing.T) {
+	var p Table
+	p.Set("smile", "\U0001F600")
+	p.Set("é", "<x>")
+	var b bytes.Buffer
+	if n, e := p.StoreXML(&b, "", "UTF-16"); n != 2 || e != nil {
+		t.Fatal("StoreXML() returned ", n, e)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte("\xfe\xff\x00<\x00?\x00x")) ||
+		!bytes.Contains(b.Bytes(), []byte("\xd8\x3d\xde\x00")) {
+		t.Fatalf("StoreXML() wrote %q", b.Bytes())
+	}
+	var q Table
+	if n, e := q.LoadXML(&b); n != 2 || e != nil || q.String() != p.String() {
+		t.Fatal("LoadXML() returned ", n, e, q.String())
+	}
+	doc := `<?xml version="1.0" encoding="UTF-16LE"?><properties>` +
+		`<entry key="k">€</entry></properties>`
+	var le []byte
+	for _, u := range utf16.Encode([]rune(doc)) {
+		le = append(le, byte(u), byte(u>>8))
+	}
+	q = Table{}
+	if n, e := q.LoadXML(bytes.NewReader(le)); n != 1 || e != nil || q.Get("k") != "€" {
+		t.Error("LoadXML() returned ", n, e, q.String())
+	}
+	if _, e := q.LoadXML(bytes.NewReader(le[:len(le)-1])); e == nil {
+		t.Error("LoadXML() read an odd number of bytes")
+	}
+}
+
+func TestCharsetReader(t *testing.T) {
+	r, e := charsetReader("ISO-8859-1", strings.NewReader("d\xe9j\xe0 vu"), false)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e = iotest.TestReader(r, []byte("déjà vu")); e != nil {
+		t.Error(e)
+	}
+	r, _ = decodeUTF16(bytes.NewReader([]byte("\xfe\xff\x00a\xd8\x3d\xde\x00")))
+	if e = iotest.TestReader(r, []byte("a\U0001F600")); e != nil {
+		t.Error(e)
+	}
+}